@@ -24,6 +24,7 @@ const (
 	sWaitingGame
 	sGamePlaying
 	sChallenge
+	sMinigame
 	sGameWin
 	sGameLose
 	sGameDraw
@@ -39,14 +40,17 @@ type Game struct {
 	roomsMenu     *ui.RoomsMenu
 	waitingMenu   *ui.WaitingMenu
 	challengeMenu *ui.ChallengeMenu
+	minigame      *ui.MinigameScene
 	gameOverMenu  *ui.GameOverMenu
+	gameMenu      *ui.GameMenu
 	state         int
 	netClient     *NetworkClient
 	grid          *ui.Grid
 	audioManager  *audio.AudioManager
 
-	mySymbol common.PlayerID // 1 for X, 2 for O
-	isMyTurn bool
+	mySymbol  common.PlayerID // 1 for X, 2 for O
+	isMyTurn  bool
+	isBotGame bool
 }
 
 // Init the game
@@ -75,7 +79,7 @@ func (g *Game) Update() error {
 	g.handleNetwork()
 
 	// Check if we lost connection in a state that requires it
-	if g.state == sRoomsMenu || g.state == sWaitingGame || g.state == sGamePlaying || g.state == sChallenge {
+	if g.state == sRoomsMenu || g.state == sWaitingGame || g.state == sGamePlaying || g.state == sChallenge || g.state == sMinigame {
 		if g.netClient == nil || !g.netClient.IsConnected() {
 			log.Println("Connection lost! Returning to Main Menu.")
 			g.state = sMainMenu
@@ -134,14 +138,80 @@ func (g *Game) Update() error {
 			g.state = sMainMenu
 		}
 
+		// Cycle through the game modes for new rooms
+		if g.roomsMenu.BtnMode.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextMode()
+		}
+
+		// Cycle through the bot difficulties
+		if g.roomsMenu.BtnDifficulty.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextDifficulty()
+		}
+
+		// Cycle through the bot engines
+		if g.roomsMenu.BtnEngine.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextEngine()
+		}
+
+		// Enable or disable hints in created rooms
+		if g.roomsMenu.BtnHints.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.ToggleHints()
+		}
+
+		// Enable or disable duels in created rooms
+		if g.roomsMenu.BtnDuel.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.ToggleDuel()
+		}
+
+		// Cycle through the sides of created rooms
+		if g.roomsMenu.BtnSide.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextSide()
+		}
+
+		// Cycle through the challenge categories and levels of created rooms
+		if g.roomsMenu.BtnCategory.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextCategory()
+		}
+		if g.roomsMenu.BtnQuizLevel.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextQuizLevel()
+		}
+
+		// Cycle through the conquest rules of created rooms
+		if g.roomsMenu.BtnPenalty.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextPenalty()
+		}
+		if g.roomsMenu.BtnConquests.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextMaxConquests()
+		}
+		if g.roomsMenu.BtnProtected.IsClicked() {
+			g.audioManager.Play("click_button")
+			g.roomsMenu.NextProtected()
+		}
+
 		// Join a bot game with a specialized ID
 		if g.roomsMenu.BtnPlayBot.IsClicked() {
 			g.audioManager.Play("click_button")
 			// Create a bot game with a specialized ID based on timestamp
-			err := g.netClient.JoinGame(fmt.Sprintf("BOT_%d", time.Now().Unix()), true)
+			join := g.roomsMenu.JoinRequest(fmt.Sprintf("BOT_%d", time.Now().Unix()))
+			join.IsBot = true
+			join.Difficulty = g.roomsMenu.Difficulty
+			join.Engine = g.roomsMenu.Engine
+			join.Side = g.roomsMenu.GameSide()
+			err := g.netClient.JoinGame(join)
 			if err != nil {
 				log.Println("Connection failed:", err)
 			}
+			g.isBotGame = true
 			g.state = sWaitingGame
 		}
 
@@ -149,10 +219,13 @@ func (g *Game) Update() error {
 		if g.roomsMenu.BtnCreateRoom.IsClicked() {
 			g.audioManager.Play("click_button")
 			newRoomId := fmt.Sprintf("%d", time.Now().Unix())
-			err := g.netClient.JoinGame(newRoomId, false)
+			join := g.roomsMenu.JoinRequest(newRoomId)
+			join.Side = g.roomsMenu.GameSide()
+			err := g.netClient.JoinGame(join)
 			if err != nil {
 				log.Println("Connection failed:", err)
 			}
+			g.isBotGame = false
 			g.state = sWaitingGame
 			g.waitingMenu.RoomId = newRoomId
 		}
@@ -174,11 +247,12 @@ func (g *Game) Update() error {
 				roomId = g.roomsMenu.Rooms[roomIndex].Id
 			}
 
-			err := g.netClient.JoinGame(roomId, false)
+			err := g.netClient.JoinGame(g.roomsMenu.JoinRequest(roomId))
 			// g.roomsMenu.RoomIndex = roomIndex
 			if err != nil {
 				log.Println("Connection failed:", err)
 			}
+			g.isBotGame = false
 			g.state = sWaitingGame
 			g.waitingMenu.RoomId = roomId
 			break
@@ -187,11 +261,12 @@ func (g *Game) Update() error {
 		// Join an existing room from the list
 		for i, room := range g.roomsMenu.Rooms {
 			if room.JoinBtn.IsClicked() {
-				err := g.netClient.JoinGame(room.Id, false)
+				err := g.netClient.JoinGame(g.roomsMenu.JoinRequest(room.Id))
 				g.roomsMenu.RoomIndex = i
 				if err != nil {
 					log.Println("Connection failed:", err)
 				}
+				g.isBotGame = false
 				g.state = sWaitingGame
 				break
 			}
@@ -217,6 +292,34 @@ func (g *Game) Update() error {
 	case sGamePlaying:
 		// Handle Game Playing state
 
+		// Answer a takeback request of the opponent
+		if g.gameMenu.ShowTakeback && g.gameMenu.TakebackAsked {
+			accept := g.gameMenu.BtnAccept.IsClicked()
+			if accept || g.gameMenu.BtnDecline.IsClicked() {
+				g.audioManager.Play("click_button")
+				if err := g.netClient.ReplyTakeback(accept); err != nil {
+					log.Println(err)
+				}
+				g.gameMenu.TakebackAsked = false
+			}
+		} else if g.gameMenu.ShowTakeback && g.gameMenu.BtnTakeback.IsClicked() {
+			// Ask the opponent to undo our last move
+			g.audioManager.Play("click_button")
+			if err := g.netClient.RequestTakeback(); err != nil {
+				log.Println(err)
+			}
+			g.gameMenu.Notice = "Takeback requested"
+		}
+
+		// Ask the server for the best moves
+		if g.isMyTurn && g.gameMenu.HintsLeft > 0 && g.gameMenu.BtnHint.IsClicked() {
+			g.audioManager.Play("click_button")
+			if err := g.netClient.RequestHint(); err != nil {
+				log.Println(err)
+			}
+			g.gameMenu.Notice = "Thinking..."
+		}
+
 		// Click on a cell
 		if !g.isMyTurn {
 			return nil
@@ -238,16 +341,28 @@ func (g *Game) Update() error {
 
 		// Update the clock
 		g.challengeMenu.Clock.Update()
-		for i, ansBtn := range g.challengeMenu.Answers {
-			if ansBtn.IsClicked() {
-				g.audioManager.Play("challenge")
-				// Send answer to server
-				err := g.netClient.AnswerChallenge(i)
-				if err != nil {
-					log.Println("Connection failed:", err)
-				}
-				g.state = sGamePlaying
+		if answer, ok := g.challengeMenu.Update(); ok {
+			g.audioManager.Play("challenge")
+			// Send answer to server
+			err := g.netClient.AnswerChallenge(answer)
+			if err != nil {
+				log.Println("Connection failed:", err)
 			}
+			g.state = sGamePlaying
+		}
+	case sMinigame:
+		// Handle Minigame state, played instead of a question
+
+		// Update the clock
+		g.minigame.Clock.Update()
+		if events, ok := g.minigame.Update(); ok {
+			g.audioManager.Play("challenge")
+			// Send the timed actions to server, which scores them
+			err := g.netClient.AnswerChallenge(common.AnswerPayload{Answer: common.NoAnswer, Events: events})
+			if err != nil {
+				log.Println("Connection failed:", err)
+			}
+			g.state = sGamePlaying
 		}
 	case sGameWin, sGameLose, sGameDraw:
 		// Handle Game Over states
@@ -290,10 +405,13 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		ui.RenderRoomsMenu(screen, g.roomsMenu)
 	case sGamePlaying:
 		// Draw Game Board
-		ui.RenderGame(screen, g.grid, g.isMyTurn)
+		ui.RenderGame(screen, g.grid, g.gameMenu, g.isMyTurn)
 	case sChallenge:
 		// Draw Challenge/Quiz Interface
 		ui.RenderChallenge(screen, g.challengeMenu)
+	case sMinigame:
+		// Draw Minigame Interface
+		ui.RenderMinigame(screen, g.minigame)
 	case sGameWin:
 		// Draw Win Screen
 		ui.RenderWin(screen, g.gameOverMenu)
@@ -332,6 +450,10 @@ func (g *Game) handleNetwork() {
 				log.Printf("Failed to unmarshal %s: %v", packet.Type, err)
 				continue
 			}
+			// Game modes available to create a room
+			g.roomsMenu.SetModes(p.Modes)
+			g.roomsMenu.SetEngines(p.Engines)
+
 			// Clear existing rooms
 			g.roomsMenu.Rooms = nil
 
@@ -353,6 +475,9 @@ func (g *Game) handleNetwork() {
 
 			g.mySymbol = p.YouAre
 			g.state = sGamePlaying // Server authorized us to start
+			g.gameMenu.Reset(!g.isBotGame, p.Hints)
+			g.gameMenu.SetConquestRules(p.Conquests)
+			g.grid.SetProtected(p.Conquests.Protected)
 			log.Printf("Game Started! I am Player %d", g.mySymbol)
 
 			// Ensure game music is playing (handle case where waiting screen was skipped)
@@ -374,11 +499,48 @@ func (g *Game) handleNetwork() {
 				continue
 			}
 
-			// Update local grid data
+			// Update local grid data, the server decides the board dimensions
+			g.grid.Resize(p.Width, p.Height)
 			g.grid.BoardData = p.Board
+			g.grid.SetSubBoards(p.SubBoards, p.ActiveX, p.ActiveY)
+			g.grid.SetHints(nil)
 			g.isMyTurn = (p.Turn == g.mySymbol)
 			log.Println("Board updated")
 
+		case common.MsgTakeback:
+			// The opponent asks to undo their last move
+			g.gameMenu.TakebackAsked = true
+
+		case common.MsgTakebackReply:
+			// The opponent answered our takeback request
+			var p common.TakebackReplyPayload
+			if err := json.Unmarshal(packet.Data, &p); err != nil {
+				log.Printf("Failed to unmarshal %s: %v", packet.Type, err)
+				continue
+			}
+
+			if p.Accept {
+				g.gameMenu.Notice = "Takeback accepted"
+			} else {
+				g.gameMenu.Notice = "Takeback declined"
+			}
+
+		case common.MsgHint:
+			// The server suggests moves for the current position
+			var p common.HintPayload
+			if err := json.Unmarshal(packet.Data, &p); err != nil {
+				log.Printf("Failed to unmarshal %s: %v", packet.Type, err)
+				continue
+			}
+
+			g.grid.SetHints(p.Moves)
+			g.gameMenu.SetHintsLeft(p.Remaining)
+			if len(p.Moves) > 0 {
+				g.gameMenu.Notice = "Best moves shaded"
+			} else {
+				g.gameMenu.Notice = "No hint available"
+			}
+
 		case common.MsgChallenge:
 			// Handle challenge trigger (quiz)
 			var payload common.ChallengePayload
@@ -387,22 +549,43 @@ func (g *Game) handleNetwork() {
 				continue
 			}
 
-			// Initialize and display challenge menu
-			g.challengeMenu = ui.NewChallengeMenu(payload)
-			g.state = sChallenge
-
-			// Start challenge timer
-			g.challengeMenu.Clock = *ui.NewTimer(common.ChallengeTime * time.Second)
-			g.challengeMenu.Clock.OnEnd = func() {
+			// Start challenge timer, running until the deadline of the server
+			clock := *ui.NewDeadlineTimer(g.netClient.LocalTime(payload.Deadline), common.ChallengeTime*time.Second)
+			clock.OnEnd = func() {
 				// Handle timer expiration
 				g.audioManager.Play("challenge")
 				g.state = sGamePlaying
-				// Send empty answer (-1) on timeout
-				err := g.netClient.AnswerChallenge(-1)
+				// Send empty answer on timeout
+				err := g.netClient.AnswerChallenge(common.AnswerPayload{Answer: common.NoAnswer})
 				if err != nil {
 					log.Println("Connection failed:", err)
 				}
 			}
+
+			// Initialize and display minigame scene or challenge menu
+			if payload.Type == common.ChallengeMinigame {
+				g.minigame = ui.NewMinigameScene(payload)
+				g.minigame.Clock = clock
+				g.state = sMinigame
+			} else {
+				g.challengeMenu = ui.NewChallengeMenu(payload)
+				g.challengeMenu.Clock = clock
+				g.state = sChallenge
+			}
+
+		case common.MsgChallengeResult:
+			// Both players learn who won the challenge, a defender still answering stops there
+			var p common.ChallengeResultPayload
+			if err := json.Unmarshal(packet.Data, &p); err != nil {
+				log.Printf("Failed to unmarshal %s: %v", packet.Type, err)
+				continue
+			}
+
+			g.gameMenu.SetChallengeResult(p, g.mySymbol)
+			if g.state == sChallenge || g.state == sMinigame {
+				g.state = sGamePlaying
+			}
+
 		case common.MsgGameOver:
 			// Handle game over result
 			var p common.GameOverPayload
@@ -446,12 +629,12 @@ func (g *Game) initUIElements() {
 	g.roomsMenu = ui.NewRoomsMenu()
 	// Initialize Game Over Menu
 	g.gameOverMenu = ui.NewGameOverMenu()
+	// Initialize Game Menu
+	g.gameMenu = ui.NewGameMenu()
 	// Initialize Waiting Menu
 	g.waitingMenu = &ui.WaitingMenu{}
-	// Initialize Game Grid with default columns
-	g.grid = &ui.Grid{
-		Col: ui.GridCol,
-	}
+	// Initialize Game Grid with default dimensions
+	g.grid = ui.NewGrid(ui.GridCol, ui.GridCol)
 }
 
 // Initialize audio manager and load sounds
@@ -20,6 +20,10 @@ type NetworkClient struct {
 	ctxCancel context.CancelFunc
 	sendMu    sync.Mutex
 
+	// Server clock minus the local clock, estimated from the pings of the server
+	clockOffset time.Duration
+	clockMu     sync.Mutex
+
 	// We buffer incoming packets so the Game Loop isn't blocked by network lag
 	incomingMessages chan common.Packet
 }
@@ -99,14 +103,9 @@ func (c *NetworkClient) GetRooms() error {
 	return nil
 }
 
-// Join a game and wait for the server to authorize us to start
-func (c *NetworkClient) JoinGame(roomID string, isBot bool) error {
-	// Build the join payload
-	joinPayload := common.JoinPayload{
-		RoomID: roomID,
-		IsBot:  isBot,
-	}
-
+// Join a game and wait for the server to authorize us to start.
+// The room settings of the request are only used by the server if the room does not exist yet.
+func (c *NetworkClient) JoinGame(joinPayload common.JoinPayload) error {
 	// Marshal the payload
 	data, err := json.Marshal(joinPayload)
 	if err != nil {
@@ -162,13 +161,9 @@ func (c *NetworkClient) PlaceSymbol(cellX, cellY int) error {
 }
 
 // AnswerChallenge answers a challenge
-func (c *NetworkClient) AnswerChallenge(answerKey int) error {
-	payload := common.AnswerPayload{
-		Answer: answerKey,
-	}
-
+func (c *NetworkClient) AnswerChallenge(answer common.AnswerPayload) error {
 	// Marshal the payload
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(answer)
 	if err != nil {
 		err = c.conn.Close(websocket.StatusInternalError, "failed to marshal answer payload")
 		return err
@@ -190,6 +185,67 @@ func (c *NetworkClient) AnswerChallenge(answerKey int) error {
 	return nil
 }
 
+// RequestTakeback asks the opponent to undo our last move
+func (c *NetworkClient) RequestTakeback() error {
+	err := c.SendPacket(common.Packet{
+		Type: common.MsgTakeback,
+		Data: nil,
+	})
+
+	// If there was an error, return it
+	if err != nil {
+		log.Println("Failed to send takeback request:", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReplyTakeback answers a takeback request of the opponent
+func (c *NetworkClient) ReplyTakeback(accept bool) error {
+	payload := common.TakebackReplyPayload{
+		Accept: accept,
+	}
+
+	// Marshal the payload
+	data, err := json.Marshal(payload)
+	if err != nil {
+		err = c.conn.Close(websocket.StatusInternalError, "failed to marshal takeback payload")
+		return err
+	}
+	packet := common.Packet{
+		Type: common.MsgTakebackReply,
+		Data: data,
+	}
+
+	// Send the packet
+	err = c.SendPacket(packet)
+
+	// If there was an error, return it
+	if err != nil {
+		log.Println("Failed to send takeback reply:", err)
+		return err
+	}
+
+	return nil
+}
+
+// RequestHint asks the server for the best moves of the position
+func (c *NetworkClient) RequestHint() error {
+	err := c.SendPacket(common.Packet{
+		Type: common.MsgHint,
+		Data: nil,
+	})
+
+	// If there was an error, return it
+	if err != nil {
+		log.Println("Failed to send hint request:", err)
+		return err
+	}
+
+	return nil
+}
+
 // listen listens for incoming packets
 func (c *NetworkClient) listen() {
 	defer func() {
@@ -223,6 +279,12 @@ func (c *NetworkClient) listen() {
 			return
 		}
 
+		// Pings are echoed right away, so that the game loop does not add to the measured latency
+		if packet.Type == common.MsgPing {
+			c.answerPing(packet)
+			continue
+		}
+
 		// Non-blocking send to channel
 		select {
 		case c.incomingMessages <- packet:
@@ -232,6 +294,32 @@ func (c *NetworkClient) listen() {
 	}
 }
 
+// answerPing echoes a ping of the server and estimates the offset of its clock
+func (c *NetworkClient) answerPing(packet common.Packet) {
+	var ping common.PingPayload
+	if err := json.Unmarshal(packet.Data, &ping); err != nil {
+		log.Printf("Failed to unmarshal %s: %v", packet.Type, err)
+		return
+	}
+
+	// The ping left the server half a round trip ago
+	serverNow := time.UnixMilli(ping.Time).Add(time.Duration(ping.RTT) * time.Millisecond / 2)
+	c.clockMu.Lock()
+	c.clockOffset = serverNow.Sub(time.Now())
+	c.clockMu.Unlock()
+
+	if err := c.SendPacket(packet); err != nil {
+		log.Println("Failed to send ping:", err)
+	}
+}
+
+// LocalTime converts a time of the server clock, in Unix milliseconds, to the local clock
+func (c *NetworkClient) LocalTime(serverMillis int64) time.Time {
+	c.clockMu.Lock()
+	defer c.clockMu.Unlock()
+	return time.UnixMilli(serverMillis).Add(-c.clockOffset)
+}
+
 // SendPacket sends a packet to the server
 func (c *NetworkClient) SendPacket(packet common.Packet) error {
 	c.sendMu.Lock()
@@ -2,28 +2,71 @@ package ui
 
 import (
 	"Goonker/common"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 const (
 	AnswerButtonY        = 150
 	ButtonSpacingY       = 20
 	ChallengeButtonWidth = 400
+
+	// Typed answers of numeric and text challenges
+	ChallengeFieldHeight = 50
+	ChallengeFieldFont   = 14
+	ChallengeSubmitY     = AnswerButtonY + ChallengeFieldHeight + ButtonSpacingY
+
+	// Reset button of ordering challenges, right of the items
+	ChallengeResetWidth   = 120
+	ChallengeResetSpacing = 20
 )
 
 // ChallengeMenu represents the UI for a challenge.
 type ChallengeMenu struct {
-	Question string
-	Answers  []Button
-	Clock    Timer
+	Type      string // One of common.ChallengeTypes
+	Category  string // Empty if unknown
+	Question  string
+	Answers   []Button   // Choices, or items to order
+	Field     *TextField // Numeric and text challenges only
+	BtnSubmit *Button    // Numeric and text challenges only
+	BtnReset  *Button    // Ordering challenges only
+	Order     []int      // Items picked so far in ordering challenges
+	Notice    string     // Short feedback message, empty if none
+	Clock     Timer
+
+	items []string // Items of ordering challenges, as sent
 }
 
 // NewChallengeMenu creates a new ChallengeMenu instance.
 func NewChallengeMenu(challenge common.ChallengePayload) *ChallengeMenu {
-	challengeMenu := &ChallengeMenu{Question: challenge.Question}
+	challengeMenu := &ChallengeMenu{Type: challenge.Type, Category: challenge.Category, Question: challenge.Question}
+	if challengeMenu.Type == "" {
+		challengeMenu.Type = common.ChallengeChoice
+	}
+	if challenge.Duel {
+		challengeMenu.Notice = "Duel: the first right answer wins the cell"
+	}
 
 	// Center buttons
 	centerX := (float64(WindowWidth) - ChallengeButtonWidth) / 2
 
+	switch challengeMenu.Type {
+	case common.ChallengeNumeric, common.ChallengeText:
+		challengeMenu.Field = NewTextField(centerX, AnswerButtonY, ChallengeButtonWidth, ChallengeFieldHeight, ChallengeFieldFont)
+		challengeMenu.Field.Focused = true
+		challengeMenu.Field.redraw()
+		challengeMenu.BtnSubmit = NewButton(centerX, ChallengeSubmitY, ChallengeButtonWidth, ButtonHeight, "Submit", SmallFontFace)
+		return challengeMenu
+	case common.ChallengeOrder:
+		challengeMenu.items = challenge.Answers
+		resetX := centerX + ChallengeButtonWidth + ChallengeResetSpacing
+		challengeMenu.BtnReset = NewButton(resetX, AnswerButtonY, ChallengeResetWidth, ButtonHeight, "Reset", SmallFontFace)
+	}
+
 	// Answer buttons
 	for i, answer := range challenge.Answers {
 		buttonHeight := float64(AnswerButtonY + i*(ButtonHeight+ButtonSpacingY))
@@ -33,3 +76,77 @@ func NewChallengeMenu(challenge common.ChallengePayload) *ChallengeMenu {
 
 	return challengeMenu
 }
+
+// Update handles the input of the challenge and returns the answer once given.
+func (m *ChallengeMenu) Update() (common.AnswerPayload, bool) {
+	switch m.Type {
+	case common.ChallengeNumeric, common.ChallengeText:
+		m.Field.Update()
+		if m.BtnSubmit.IsClicked() || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			return m.Submit()
+		}
+	case common.ChallengeOrder:
+		if m.BtnReset.IsClicked() {
+			m.ResetOrder()
+		}
+		for i, item := range m.Answers {
+			if item.IsClicked() && m.Pick(i) {
+				return common.AnswerPayload{Answer: common.NoAnswer, Order: m.Order}, true
+			}
+		}
+	default:
+		for i, ansBtn := range m.Answers {
+			if ansBtn.IsClicked() {
+				return common.AnswerPayload{Answer: i}, true
+			}
+		}
+	}
+	return common.AnswerPayload{}, false
+}
+
+// Submit returns the answer typed in the field, or false with a notice if it can not be sent.
+func (m *ChallengeMenu) Submit() (common.AnswerPayload, bool) {
+	typed := strings.TrimSpace(m.Field.Text)
+	if typed == "" {
+		m.Notice = "Type your answer"
+		return common.AnswerPayload{}, false
+	}
+
+	answer := common.AnswerPayload{Answer: common.NoAnswer}
+	if m.Type != common.ChallengeNumeric {
+		answer.Text = typed
+		return answer, true
+	}
+
+	// Decimal commas are accepted
+	number, err := strconv.ParseFloat(strings.ReplaceAll(typed, ",", "."), 64)
+	if err != nil {
+		m.Notice = "Type a number"
+		return common.AnswerPayload{}, false
+	}
+	answer.Number = &number
+	return answer, true
+}
+
+// Pick appends an item to the order, and returns true once every item is picked.
+func (m *ChallengeMenu) Pick(i int) bool {
+	for _, picked := range m.Order {
+		if picked == i {
+			return false
+		}
+	}
+
+	m.Order = append(m.Order, i)
+	item := m.Answers[i]
+	m.Answers[i] = *NewButton(item.X, item.Y, item.Width, item.Height, fmt.Sprintf("%d. %s", len(m.Order), m.items[i]), SmallFontFace)
+	return len(m.Order) == len(m.items)
+}
+
+// ResetOrder unpicks every item.
+func (m *ChallengeMenu) ResetOrder() {
+	for _, i := range m.Order {
+		item := m.Answers[i]
+		m.Answers[i] = *NewButton(item.X, item.Y, item.Width, item.Height, m.items[i], SmallFontFace)
+	}
+	m.Order = nil
+}
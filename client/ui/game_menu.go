@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"Goonker/common"
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// Side panel positions, left of the grid
+const (
+	GameMenuBtnX          = (float64(WindowWidth)/2 - gridSize/2 - ButtonWidth) / 2
+	GameMenuTakebackBtnY  = 380.0
+	GameMenuAcceptBtnY    = 300.0
+	GameMenuDeclineBtnY   = 380.0
+	GameMenuPromptTextY   = 260.0
+	GameMenuNoticeTextY   = 220.0
+	GameMenuAnswerTextY   = 240.0
+	GameMenuRulesTextY    = 40.0
+	GameMenuRulesLineH    = 20.0
+	GameMenuHintBtnY      = 460.0
+	GameMenuTakebackLabel = "Takeback"
+)
+
+// GameMenu represents the controls displayed next to the grid during a game.
+type GameMenu struct {
+	BtnTakeback *Button
+	BtnAccept   *Button
+	BtnDecline  *Button
+	BtnHint     *Button
+
+	ShowTakeback  bool     // Takebacks are only available against another player
+	TakebackAsked bool     // The opponent asked for a takeback
+	HintsLeft     int      // Hints the player may still ask for
+	Notice        string   // Short feedback message, empty if none
+	Answer        string   // Right answer of the last challenge, empty if none
+	Rules         []string // Conquest rules of the room, one per line
+}
+
+// NewGameMenu creates a new GameMenu instance.
+func NewGameMenu() *GameMenu {
+	menu := &GameMenu{}
+
+	// Create buttons
+	menu.BtnTakeback = NewButton(GameMenuBtnX, GameMenuTakebackBtnY, ButtonWidth, ButtonHeight, GameMenuTakebackLabel, BigFontFace)
+	menu.BtnAccept = NewButton(GameMenuBtnX, GameMenuAcceptBtnY, ButtonWidth, ButtonHeight, "Accept", BigFontFace)
+	menu.BtnDecline = NewButton(GameMenuBtnX, GameMenuDeclineBtnY, ButtonWidth, ButtonHeight, "Decline", BigFontFace)
+	menu.SetHintsLeft(0)
+
+	return menu
+}
+
+// Reset clears the state left by a previous game.
+func (m *GameMenu) Reset(showTakeback bool, hints int) {
+	m.ShowTakeback = showTakeback
+	m.TakebackAsked = false
+	m.Notice = ""
+	m.Answer = ""
+	m.SetHintsLeft(hints)
+}
+
+// SetConquestRules describes the conquest rules of the room, announced at the start of the game.
+func (m *GameMenu) SetConquestRules(rules common.ConquestRules) {
+	switch rules.Penalty {
+	case common.PenaltyCell:
+		m.Rules = []string{"Failed conquest: lose a cell"}
+	case common.PenaltyCooldown:
+		m.Rules = []string{fmt.Sprintf("Failed conquest: cell safe %d turns", rules.Cooldown)}
+	default:
+		m.Rules = []string{"Failed conquest: lose the turn"}
+	}
+	if rules.MaxConquests > 0 {
+		m.Rules = append(m.Rules, fmt.Sprintf("Conquests: %d per player", rules.MaxConquests))
+	}
+	if len(rules.Protected) > 0 {
+		m.Rules = append(m.Rules, fmt.Sprintf("Protected cells: %d", len(rules.Protected)))
+	}
+}
+
+// SetChallengeResult tells the player me who won the last challenge, and its right answer.
+func (m *GameMenu) SetChallengeResult(result common.ChallengeResultPayload, me common.PlayerID) {
+	switch {
+	case result.Duel && result.Winner == me:
+		m.Notice = "You won the duel"
+	case result.Duel && result.Winner == common.Empty:
+		m.Notice = "Nobody won the duel"
+	case result.Duel:
+		m.Notice = "Opponent won the duel"
+	case result.Attacker == me && result.Winner == me:
+		m.Notice = "Cell conquered"
+	case result.Attacker == me:
+		m.Notice = "Conquest failed"
+	case result.Winner == result.Attacker:
+		m.Notice = "Opponent took a cell"
+	default:
+		m.Notice = "Opponent failed a conquest"
+	}
+
+	m.Answer = ""
+	if result.Answer != "" {
+		m.Answer = fmt.Sprintf("Answer: %s", result.Answer)
+	}
+}
+
+// SetHintsLeft updates the number of hints shown on the hint button.
+func (m *GameMenu) SetHintsLeft(hints int) {
+	m.HintsLeft = hints
+	m.BtnHint = NewButton(GameMenuBtnX, GameMenuHintBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Hint (%d)", hints), BigFontFace)
+}
+
+// Draw the game menu to the screen.
+func (m *GameMenu) Draw(screen *ebiten.Image) {
+	for i, rule := range m.Rules {
+		drawPanelText(screen, rule, GameMenuRulesTextY+float64(i)*GameMenuRulesLineH)
+	}
+
+	if m.Notice != "" {
+		drawPanelText(screen, m.Notice, GameMenuNoticeTextY)
+	}
+	if m.Answer != "" {
+		drawPanelText(screen, m.Answer, GameMenuAnswerTextY)
+	}
+
+	if m.HintsLeft > 0 {
+		m.BtnHint.Draw(screen)
+	}
+
+	if !m.ShowTakeback {
+		return
+	}
+
+	if m.TakebackAsked {
+		drawPanelText(screen, "Opponent asks for a takeback", GameMenuPromptTextY)
+		m.BtnAccept.Draw(screen)
+		m.BtnDecline.Draw(screen)
+		return
+	}
+
+	m.BtnTakeback.Draw(screen)
+}
+
+// drawPanelText draws a line of text centered in the side panel.
+func drawPanelText(screen *ebiten.Image, msg string, y float64) {
+	op := &text.DrawOptions{}
+	w, _ := text.Measure(msg, SmallGameFont, op.LineSpacing)
+	x := (float64(WindowWidth)/2 - gridSize/2 - w) / 2
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(color.Black)
+	text.Draw(screen, msg, SmallGameFont, op)
+}
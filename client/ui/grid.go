@@ -9,8 +9,105 @@ import (
 
 // Grid represents the game board grid.
 type Grid struct {
-	Col       int
-	BoardData [GridCol][GridCol]common.PlayerID
+	Cols      int
+	Rows      int
+	BoardData [][]common.PlayerID // Indexed as BoardData[x][y]
+
+	// Nested boards (Ultimate mode), SubBoards is nil for a plain grid
+	SubBoards [][]common.PlayerID
+	ActiveX   int
+	ActiveY   int
+
+	// Suggested moves of the last hint, best first
+	Hints []common.HintMove
+
+	// Cells that can not be conquered in this game
+	Protected []common.Cell
+}
+
+// NewGrid creates an empty grid with the given dimensions.
+func NewGrid(cols, rows int) *Grid {
+	g := &Grid{}
+	g.Resize(cols, rows)
+	return g
+}
+
+// Resize changes the grid dimensions and redraws the grid image if needed.
+func (g *Grid) Resize(cols, rows int) {
+	if g.Cols == cols && g.Rows == rows && GridImage != nil {
+		return
+	}
+
+	g.Cols, g.Rows = cols, rows
+	g.BoardData = make([][]common.PlayerID, cols)
+	for x := range g.BoardData {
+		g.BoardData[x] = make([]common.PlayerID, rows)
+	}
+
+	DrawGrid(cols, rows)
+}
+
+// SetSubBoards updates the nested boards state, nil subBoards means a plain grid.
+func (g *Grid) SetSubBoards(subBoards [][]common.PlayerID, activeX, activeY int) {
+	wasNested := g.IsNested()
+	g.SubBoards = subBoards
+	g.ActiveX, g.ActiveY = activeX, activeY
+
+	if g.IsNested() && (!wasNested || NestedGridImage == nil) {
+		DrawNestedGrid(g.Cols, g.Rows, g.SubSize())
+	}
+}
+
+// SetHints shades the suggested cells until the next board update.
+func (g *Grid) SetHints(moves []common.HintMove) {
+	g.Hints = nil
+	for _, move := range moves {
+		if move.X >= 0 && move.X < g.Cols && move.Y >= 0 && move.Y < g.Rows {
+			g.Hints = append(g.Hints, move)
+		}
+	}
+}
+
+// SetProtected shades the cells that can not be conquered, for the whole game.
+// The grid is only resized by the first board update, so the cells are checked when rendered.
+func (g *Grid) SetProtected(cells []common.Cell) {
+	g.Protected = cells
+}
+
+// IsNested checks if the grid is made of sub-boards.
+func (g *Grid) IsNested() bool {
+	return len(g.SubBoards) > 0
+}
+
+// SubSize returns the number of cells on a side of a sub-board.
+func (g *Grid) SubSize() int {
+	return g.Cols / len(g.SubBoards)
+}
+
+// IsSubBoardPlayable checks if the sub-board (sx, sy) may receive the next move.
+func (g *Grid) IsSubBoardPlayable(sx, sy int) bool {
+	if g.SubBoards[sx][sy] != common.Empty {
+		return false
+	}
+	if g.ActiveX >= 0 && (sx != g.ActiveX || sy != g.ActiveY) {
+		return false
+	}
+
+	// A full sub-board cannot be played either
+	subSize := g.SubSize()
+	for x := sx * subSize; x < (sx+1)*subSize; x++ {
+		for y := sy * subSize; y < (sy+1)*subSize; y++ {
+			if g.BoardData[x][y] == common.Empty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CellSize returns the size in pixels of a single cell.
+func (g *Grid) CellSize() float64 {
+	return float64(GridImage.Bounds().Dx()) / float64(g.Cols)
 }
 
 // OnClick checks for mouse input and returns the clicked cell coordinates.
@@ -41,14 +138,14 @@ func (g *Grid) GetCellAt(mx, my int) (int, int, bool) {
 		return -1, -1, false
 	}
 
-	cellSize := gridW / GridCol
+	cellSize := gridW / g.Cols
 
 	// Determine cell coordinates
 	cellX := localX / cellSize
 	cellY := localY / cellSize
 
 	// Clamp values just to be safe (though equality check above handles most)
-	if cellX >= g.Col || cellY >= g.Col {
+	if cellX >= g.Cols || cellY >= g.Rows {
 		return -1, -1, false
 	}
 
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"Goonker/common"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ func TestGridLogic(t *testing.T) {
 	}()
 	InitImages()
 
-	grid := &Grid{Col: 3}
+	grid := NewGrid(3, 3)
 
 	// Get dimensions we expect
 	gridW := GridImage.Bounds().Dx()
@@ -66,4 +67,86 @@ func TestGridLogic(t *testing.T) {
 	if ok {
 		t.Error("Expected invalid click when below grid")
 	}
+
+	// Test 5: Wider board keeps square cells
+	grid = NewGrid(5, 4)
+	gridW = GridImage.Bounds().Dx()
+	gridH = GridImage.Bounds().Dy()
+	cellSize = gridW / 5
+	if gridH != cellSize*4 {
+		t.Errorf("Expected grid height %d, got %d", cellSize*4, gridH)
+	}
+
+	offsetX = (WindowWidth - gridW) / 2
+	offsetY = (WindowHeight - gridH) / 2
+	mx = offsetX + 4*cellSize + cellSize/2
+	my = offsetY + 3*cellSize + cellSize/2
+
+	x, y, ok = grid.GetCellAt(mx, my)
+	if !ok || x != 4 || y != 3 {
+		t.Errorf("Expected 4,3 got %d,%d (ok=%v)", x, y, ok)
+	}
+}
+
+func TestSubBoardPlayable(t *testing.T) {
+	// Build the grid data by hand, no image is needed for the logic
+	grid := &Grid{Cols: 9, Rows: 9}
+	grid.BoardData = make([][]common.PlayerID, 9)
+	for x := range grid.BoardData {
+		grid.BoardData[x] = make([]common.PlayerID, 9)
+	}
+	grid.SubBoards = [][]common.PlayerID{
+		{common.P1, common.Empty, common.Empty},
+		{common.Empty, common.Empty, common.Empty},
+		{common.Empty, common.Empty, common.Empty},
+	}
+	grid.ActiveX, grid.ActiveY = -1, -1
+
+	if grid.SubSize() != 3 {
+		t.Errorf("Expected sub-board size 3, got %d", grid.SubSize())
+	}
+
+	// Won sub-boards are never playable
+	if grid.IsSubBoardPlayable(0, 0) {
+		t.Error("Expected won sub-board to not be playable")
+	}
+	if !grid.IsSubBoardPlayable(1, 1) {
+		t.Error("Expected open sub-board to be playable on a free move")
+	}
+
+	// Only the active sub-board is playable
+	grid.ActiveX, grid.ActiveY = 2, 1
+	if grid.IsSubBoardPlayable(1, 1) {
+		t.Error("Expected inactive sub-board to not be playable")
+	}
+	if !grid.IsSubBoardPlayable(2, 1) {
+		t.Error("Expected active sub-board to be playable")
+	}
+
+	// Full sub-boards are not playable
+	for x := 6; x < 9; x++ {
+		for y := 3; y < 6; y++ {
+			grid.BoardData[x][y] = common.P2
+		}
+	}
+	if grid.IsSubBoardPlayable(2, 1) {
+		t.Error("Expected full sub-board to not be playable")
+	}
+}
+
+func TestGridHints(t *testing.T) {
+	grid := &Grid{Cols: 3, Rows: 3}
+
+	grid.SetHints([]common.HintMove{{X: 1, Y: 1}, {X: 3, Y: 0}, {X: 0, Y: 2, Conquest: true}})
+	if len(grid.Hints) != 2 {
+		t.Fatalf("Expected the hints outside the grid to be dropped, got %v", grid.Hints)
+	}
+	if grid.Hints[0].X != 1 || grid.Hints[1].Y != 2 {
+		t.Errorf("Expected the hints to keep their rank, got %v", grid.Hints)
+	}
+
+	grid.SetHints(nil)
+	if len(grid.Hints) != 0 {
+		t.Error("Expected the hints to be cleared")
+	}
 }
@@ -30,6 +30,9 @@ const (
 	WheelMinAlpha     = 50
 	WheelAlphaRange   = 205
 
+	// Nested grid
+	nestedLineWidth = 2 * lineWidth
+
 	// Menu
 	TitleYRatio      = 5
 	TitleYRatioRooms = 8
@@ -39,6 +42,7 @@ var (
 	BigFontFace      font.Face
 	SmallFontFace    font.Face
 	GridImage        *ebiten.Image
+	NestedGridImage  *ebiten.Image
 	CircleImage      *ebiten.Image
 	CrossImage       *ebiten.Image
 	WheelImage       *ebiten.Image
@@ -56,7 +60,7 @@ var (
 func InitImages() {
 	initFont()
 
-	DrawGrid(GridCol)
+	DrawGrid(GridCol, GridCol)
 	DrawCircle()
 	DrawCross()
 	DrawWaitingWheel()
@@ -91,9 +95,13 @@ func initFont() {
 	})
 }
 
-// DrawGrid draws the grid image.
-func DrawGrid(col int) {
-	dc := gg.NewContext(gridSize, gridSize)
+// DrawGrid draws the grid image for a board of cols x rows cells.
+func DrawGrid(cols, rows int) {
+	// Cells stay square, the longest side of the board fills the grid area
+	cell := gridSize / max(cols, rows)
+	width, height := cell*cols, cell*rows
+
+	dc := gg.NewContext(width, height)
 
 	// Draw the background
 	dc.SetHexColor(gridBackgroundColor)
@@ -104,59 +112,83 @@ func DrawGrid(col int) {
 	dc.SetLineWidth(lineWidth)
 	dc.SetLineCap(gg.LineCapRound)
 
-	cellSize := float64(gridSize) / float64(col)
-
-	for i := range col {
-		pos := float64(i) * cellSize
-
-		// Vertical line
-		dc.DrawLine(pos, 0, pos, float64(gridSize))
+	// Vertical lines
+	for i := range cols {
+		pos := float64(i * cell)
+		dc.DrawLine(pos, 0, pos, float64(height))
+	}
 
-		// Horizontal line
-		dc.DrawLine(0, pos, float64(gridSize), pos)
+	// Horizontal lines
+	for i := range rows {
+		pos := float64(i * cell)
+		dc.DrawLine(0, pos, float64(width), pos)
 	}
 	dc.Stroke()
 
 	// Outer border
 	offset := lineWidth / 2
-	dc.DrawRectangle(offset, offset, float64(gridSize)-lineWidth, float64(gridSize)-lineWidth)
+	dc.DrawRectangle(offset, offset, float64(width)-lineWidth, float64(height)-lineWidth)
 	dc.Stroke()
 
 	GridImage = ebiten.NewImageFromImage(dc.Image())
 }
 
-// DrawCircle draws the circle symbol image.
+// DrawNestedGrid draws the thick sub-board separators, to be drawn on top of the grid image.
+func DrawNestedGrid(cols, rows, subSize int) {
+	cell := gridSize / max(cols, rows)
+	width, height := cell*cols, cell*rows
+	subCell := float64(cell * subSize)
+
+	dc := gg.NewContext(width, height)
+
+	dc.SetHexColor(gridBorderColor)
+	dc.SetLineWidth(nestedLineWidth)
+	dc.SetLineCap(gg.LineCapRound)
+
+	// Separators between sub-boards only, the outer border is part of the grid
+	for i := 1; i < cols/subSize; i++ {
+		pos := float64(i) * subCell
+		dc.DrawLine(pos, 0, pos, float64(height))
+	}
+	for i := 1; i < rows/subSize; i++ {
+		pos := float64(i) * subCell
+		dc.DrawLine(0, pos, float64(width), pos)
+	}
+	dc.Stroke()
+
+	NestedGridImage = ebiten.NewImageFromImage(dc.Image())
+}
+
+// DrawCircle draws the circle symbol image, sized for a single cell.
 func DrawCircle() {
-	dc := gg.NewContext(gridSize, gridSize)
+	dc := gg.NewContext(cellSize, cellSize)
 
-	centerX := float64(cellSize + cellSize/2)
-	centerY := float64(cellSize + cellSize/2)
+	center := float64(cellSize) / 2
 
 	dc.SetHexColor(gridBorderColor)
 	dc.SetLineWidth(lineWidth)
-	dc.DrawCircle(centerX, centerY, symbolLength)
+	dc.DrawCircle(center, center, symbolLength)
 
 	dc.Stroke()
 
 	CircleImage = ebiten.NewImageFromImage(dc.Image())
 }
 
-// DrawCross draws the cross symbol image.
+// DrawCross draws the cross symbol image, sized for a single cell.
 func DrawCross() {
-	dc := gg.NewContext(gridSize, gridSize)
+	dc := gg.NewContext(cellSize, cellSize)
 
 	dc.SetHexColor(gridBorderColor)
 	dc.SetLineWidth(lineWidth)
 	dc.SetLineCap(gg.LineCapRound)
 
-	centerX := float64(cellSize + cellSize/2)
-	centerY := float64(cellSize + cellSize/2)
+	center := float64(cellSize) / 2
 
 	// Diagonal from top-left to bottom-right \
-	dc.DrawLine(centerX-symbolLength, centerY-symbolLength, centerX+symbolLength, centerY+symbolLength)
+	dc.DrawLine(center-symbolLength, center-symbolLength, center+symbolLength, center+symbolLength)
 
 	// Diagonal from bottom-left to top-right /
-	dc.DrawLine(centerX-symbolLength, centerY+symbolLength, centerX+symbolLength, centerY-symbolLength)
+	dc.DrawLine(center-symbolLength, center+symbolLength, center+symbolLength, center-symbolLength)
 
 	dc.Stroke()
 
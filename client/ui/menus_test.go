@@ -27,6 +27,83 @@ func TestMenuConstructors(t *testing.T) {
 	if rm.RoomField == nil {
 		t.Error("RoomField not initialized")
 	}
+	if rm.BtnMode == nil || rm.Mode != common.ModeClassic {
+		t.Error("Rooms Menu mode not initialized")
+	}
+	rm.SetModes([]string{common.ModeClassic, common.ModeUltimate})
+	if rm.Mode != common.ModeClassic {
+		t.Errorf("Expected selected mode to be kept, got %s", rm.Mode)
+	}
+	rm.NextMode()
+	if rm.Mode != common.ModeUltimate {
+		t.Errorf("Expected mode %s after cycling, got %s", common.ModeUltimate, rm.Mode)
+	}
+	if rm.BtnDifficulty == nil || rm.Difficulty != common.DifficultyMedium {
+		t.Error("Rooms Menu difficulty not initialized")
+	}
+	rm.NextDifficulty()
+	if rm.Difficulty != common.DifficultyHard {
+		t.Errorf("Expected difficulty %s after cycling, got %s", common.DifficultyHard, rm.Difficulty)
+	}
+	if rm.BtnEngine == nil || rm.Engine != common.EngineMinimax {
+		t.Error("Rooms Menu engine not initialized")
+	}
+	rm.NextEngine()
+	if rm.Engine != common.EngineMCTS {
+		t.Errorf("Expected engine %s after cycling, got %s", common.EngineMCTS, rm.Engine)
+	}
+	rm.SetEngines([]string{common.EngineMinimax, common.EngineMCTS, common.EngineExternal})
+	rm.NextEngine()
+	if rm.Engine != common.EngineExternal {
+		t.Errorf("Expected engine %s advertised by the server, got %s", common.EngineExternal, rm.Engine)
+	}
+	if rm.BtnHints == nil || !rm.Hints {
+		t.Error("Rooms Menu hints not initialized")
+	}
+	rm.ToggleHints()
+	if rm.Hints {
+		t.Error("Expected hints to be disabled after toggling")
+	}
+	if rm.BtnDuel == nil || rm.Duel {
+		t.Error("Rooms Menu duel not initialized")
+	}
+	rm.ToggleDuel()
+	if !rm.Duel {
+		t.Error("Expected duels to be enabled after toggling")
+	}
+	if rm.BtnSide == nil || rm.Side != common.SideX {
+		t.Error("Rooms Menu side not initialized")
+	}
+	rm.NextSide()
+	if rm.Side != common.SideO || rm.GameSide() != common.SideO {
+		t.Errorf("Expected side %s after cycling, got %s", common.SideO, rm.Side)
+	}
+	if filter := rm.ChallengeFilter(); len(filter.Categories) != 0 || filter.MinDifficulty != 0 || filter.MaxDifficulty != 0 {
+		t.Errorf("Expected every challenge to be allowed, got %+v", filter)
+	}
+	rm.NextCategory()
+	rm.NextQuizLevel()
+	filter := rm.ChallengeFilter()
+	if len(filter.Categories) != 1 || filter.Categories[0] != common.Categories[0] || filter.MaxDifficulty != common.ChallengeEasy {
+		t.Errorf("Expected easy %s challenges, got %+v", common.Categories[0], filter)
+	}
+	if rules := rm.ConquestRules(); rules.Penalty != common.PenaltyTurn || rules.MaxConquests != 0 || len(rules.Protected) != 0 {
+		t.Errorf("Expected the default conquest rules, got %+v", rules)
+	}
+	rm.NextPenalty()
+	rm.NextMaxConquests()
+	rm.NextProtected()
+	rules := rm.ConquestRules()
+	if rules.Penalty != common.PenaltyCell || rules.MaxConquests != 1 || len(rules.Protected) != 1 || rules.Protected[0] != (common.Cell{X: 4, Y: 4}) {
+		t.Errorf("Expected one conquest, the cell penalty and the ultimate center protected, got %+v", rules)
+	}
+	if join := rm.JoinRequest("42"); join.RoomID != "42" || join.Mode != common.ModeUltimate || !join.NoHints || !join.Duel || join.Conquests.Penalty != common.PenaltyCell {
+		t.Errorf("Expected the settings of the menu in the join request, got %+v", join)
+	}
+	rm.SetSide(SideAlternate)
+	if first, second := rm.GameSide(), rm.GameSide(); first != common.SideX || second != common.SideO {
+		t.Errorf("Expected alternate sides x then o, got %s then %s", first, second)
+	}
 
 	// Game Over Menu
 	gom := NewGameOverMenu()
@@ -34,6 +111,33 @@ func TestMenuConstructors(t *testing.T) {
 		t.Error("Game Over Menu back button not initialized")
 	}
 
+	// Game Menu
+	gm := NewGameMenu()
+	if gm.BtnTakeback == nil || gm.BtnAccept == nil || gm.BtnDecline == nil || gm.BtnHint == nil {
+		t.Error("Game Menu buttons not initialized")
+	}
+	gm.TakebackAsked = true
+	gm.Notice = "notice"
+	gm.Reset(true, 3)
+	if !gm.ShowTakeback || gm.TakebackAsked || gm.Notice != "" {
+		t.Error("Game Menu state not reset")
+	}
+	if gm.BtnHint == nil || gm.HintsLeft != 3 {
+		t.Error("Game Menu hints not reset")
+	}
+	gm.SetChallengeResult(common.ChallengeResultPayload{Attacker: common.P1, Winner: common.P2, Answer: "Bern", Duel: true}, common.P2)
+	if gm.Notice != "You won the duel" || gm.Answer != "Answer: Bern" {
+		t.Errorf("Unexpected duel result notice %q, %q", gm.Notice, gm.Answer)
+	}
+	gm.SetChallengeResult(common.ChallengeResultPayload{Attacker: common.P1, Winner: common.P1}, common.P2)
+	if gm.Notice != "Opponent took a cell" || gm.Answer != "" {
+		t.Errorf("Unexpected challenge result notice %q, %q", gm.Notice, gm.Answer)
+	}
+	gm.SetConquestRules(common.ConquestRules{Penalty: common.PenaltyCooldown, Cooldown: 2, Protected: []common.Cell{{X: 1, Y: 1}}})
+	if len(gm.Rules) != 2 || gm.Rules[0] != "Failed conquest: cell safe 2 turns" || gm.Rules[1] != "Protected cells: 1" {
+		t.Errorf("Unexpected conquest rules %q", gm.Rules)
+	}
+
 	// Challenge Menu
 	dummyChallenge := common.ChallengePayload{
 		Question: "Q?",
@@ -46,4 +150,36 @@ func TestMenuConstructors(t *testing.T) {
 	if cm.Question != "Q?" {
 		t.Error("Challenge Question mismatch")
 	}
+
+	// Typed challenges
+	numeric := NewChallengeMenu(common.ChallengePayload{Type: common.ChallengeNumeric, Question: "Q?"})
+	if numeric.Field == nil || numeric.BtnSubmit == nil || len(numeric.Answers) != 0 {
+		t.Fatal("Numeric challenge field not initialized")
+	}
+	numeric.Field.Insert("abc")
+	if _, ok := numeric.Submit(); ok || numeric.Notice == "" {
+		t.Error("Expected a non-numeric answer to be refused")
+	}
+	numeric.Field.Text = "1,5"
+	if answer, ok := numeric.Submit(); !ok || answer.Number == nil || *answer.Number != 1.5 {
+		t.Errorf("Expected the number 1.5, got %+v", answer)
+	}
+	text := NewChallengeMenu(common.ChallengePayload{Type: common.ChallengeText, Question: "Q?"})
+	text.Field.Insert("Bern")
+	if answer, ok := text.Submit(); !ok || answer.Text != "Bern" || answer.Answer != common.NoAnswer {
+		t.Errorf("Expected the typed text, got %+v", answer)
+	}
+
+	// Ordering challenges
+	order := NewChallengeMenu(common.ChallengePayload{Type: common.ChallengeOrder, Question: "Q?", Answers: []string{"B", "A"}})
+	if order.BtnReset == nil || len(order.Answers) != 2 {
+		t.Fatal("Ordering challenge buttons not initialized")
+	}
+	if order.Pick(1) || order.Pick(1) {
+		t.Error("Expected the order to be incomplete")
+	}
+	order.ResetOrder()
+	if order.Pick(1) || !order.Pick(0) || len(order.Order) != 2 || order.Order[0] != 1 {
+		t.Errorf("Expected the order [1 0], got %v", order.Order)
+	}
 }
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"Goonker/common"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// Reaction area, turning green at the signal
+	ReactionAreaWidth  = 400
+	ReactionAreaHeight = 250
+	ReactionAreaX      = (float64(WindowWidth) - ReactionAreaWidth) / 2
+	ReactionAreaY      = 150
+
+	// Memory grid
+	MemoryCellSize    = 80
+	MemoryCellSpacing = 10
+	MemoryGridSize    = GridCol*MemoryCellSize + (GridCol-1)*MemoryCellSpacing
+	MemoryGridX       = (float64(WindowWidth) - MemoryGridSize) / 2
+	MemoryGridY       = 150
+
+	// Bar track
+	BarTrackWidth  = 500
+	BarTrackHeight = 40
+	BarTrackX      = (float64(WindowWidth) - BarTrackWidth) / 2
+	BarTrackY      = 250
+	BarWidth       = 6
+)
+
+var (
+	minigameIdleColor   = color.RGBA{150, 150, 150, 255}
+	minigameActiveColor = color.RGBA{60, 180, 75, 255}
+	minigamePickedColor = color.RGBA{70, 110, 200, 255}
+	minigameBarColor    = color.Black
+)
+
+// MinigameScene represents the UI of a minigame, timing the actions of the player.
+type MinigameScene struct {
+	Question string
+	Minigame common.MinigamePayload
+	Events   []common.MinigameEvent // Actions so far, sent once the minigame is over
+	Clock    Timer
+
+	start time.Time
+}
+
+// NewMinigameScene creates a new MinigameScene instance, its clock starting now.
+func NewMinigameScene(challenge common.ChallengePayload) *MinigameScene {
+	scene := &MinigameScene{Question: challenge.Question, start: time.Now()}
+	if challenge.Minigame != nil {
+		scene.Minigame = *challenge.Minigame
+	}
+	return scene
+}
+
+// Elapsed returns the milliseconds since the minigame is shown.
+func (s *MinigameScene) Elapsed() int {
+	return int(time.Since(s.start).Milliseconds())
+}
+
+// Update handles the input of the minigame and returns the events once it is over.
+func (s *MinigameScene) Update() ([]common.MinigameEvent, bool) {
+	at := s.Elapsed()
+	switch s.Minigame.Kind {
+	case common.MinigameMemory:
+		if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			return nil, false
+		}
+		mx, my := ebiten.CursorPosition()
+		if cell, ok := memoryCellAt(float64(mx), float64(my)); ok && s.Record(at, cell) {
+			return s.Events, true
+		}
+	default:
+		pressed := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+		if pressed && s.Record(at, 0) {
+			return s.Events, true
+		}
+	}
+	return nil, false
+}
+
+// Record adds an action of the player at a time, and returns true once the minigame is over.
+// Cells picked while the memory sequence is shown are ignored.
+func (s *MinigameScene) Record(at, cell int) bool {
+	if s.Minigame.Kind != common.MinigameMemory {
+		s.Events = append(s.Events, common.MinigameEvent{At: at})
+		return true
+	}
+
+	if at < len(s.Minigame.Sequence)*s.Minigame.Step {
+		return false
+	}
+	s.Events = append(s.Events, common.MinigameEvent{At: at, Cell: cell})
+	return len(s.Events) == len(s.Minigame.Sequence)
+}
+
+// LitCell returns the cell of the memory sequence shown at a time, -1 once the sequence is over.
+func (s *MinigameScene) LitCell(at int) int {
+	if s.Minigame.Step <= 0 {
+		return -1
+	}
+	if step := at / s.Minigame.Step; step < len(s.Minigame.Sequence) {
+		return s.Minigame.Sequence[step]
+	}
+	return -1
+}
+
+// Draw the minigame to the screen.
+func (s *MinigameScene) Draw(screen *ebiten.Image) {
+	at := s.Elapsed()
+	switch s.Minigame.Kind {
+	case common.MinigameReaction:
+		areaColor := minigameIdleColor
+		if at >= s.Minigame.Delay {
+			areaColor = minigameActiveColor
+		}
+		drawRect(screen, ReactionAreaX, ReactionAreaY, ReactionAreaWidth, ReactionAreaHeight, areaColor)
+	case common.MinigameMemory:
+		lit := s.LitCell(at)
+		for cell := range GridCol * GridCol {
+			cellColor := minigameIdleColor
+			if cell == lit {
+				cellColor = minigameActiveColor
+			}
+			for _, event := range s.Events {
+				if event.Cell == cell {
+					cellColor = minigamePickedColor
+				}
+			}
+			x, y := memoryCellPosition(cell)
+			drawRect(screen, x, y, MemoryCellSize, MemoryCellSize, cellColor)
+		}
+	case common.MinigameBar:
+		drawRect(screen, BarTrackX, BarTrackY, BarTrackWidth, BarTrackHeight, minigameIdleColor)
+		drawRect(screen, BarTrackX+s.Minigame.Target*BarTrackWidth, BarTrackY, s.Minigame.Width*BarTrackWidth, BarTrackHeight, minigameActiveColor)
+		position := s.Minigame.BarPosition(at)
+		drawRect(screen, BarTrackX+position*BarTrackWidth-BarWidth/2, BarTrackY, BarWidth, BarTrackHeight, minigameBarColor)
+	}
+}
+
+// memoryCellPosition returns the top left corner of a cell of the memory grid, indexed x + 3*y.
+func memoryCellPosition(cell int) (float64, float64) {
+	x, y := cell%GridCol, cell/GridCol
+	return MemoryGridX + float64(x*(MemoryCellSize+MemoryCellSpacing)), MemoryGridY + float64(y*(MemoryCellSize+MemoryCellSpacing))
+}
+
+// memoryCellAt returns the cell of the memory grid at a position, false if there is none.
+func memoryCellAt(x, y float64) (int, bool) {
+	for cell := range GridCol * GridCol {
+		cx, cy := memoryCellPosition(cell)
+		if x >= cx && x <= cx+MemoryCellSize && y >= cy && y <= cy+MemoryCellSize {
+			return cell, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"Goonker/common"
+	"testing"
+)
+
+func TestMinigameSceneMemory(t *testing.T) {
+	minigame := &common.MinigamePayload{Kind: common.MinigameMemory, Sequence: []int{4, 0}, Step: 500}
+	scene := NewMinigameScene(common.ChallengePayload{Type: common.ChallengeMinigame, Question: "Repeat", Minigame: minigame})
+
+	if lit := scene.LitCell(600); lit != 0 {
+		t.Errorf("Expected cell 0 to be lit at 600ms, got %d", lit)
+	}
+	if lit := scene.LitCell(1000); lit != -1 {
+		t.Errorf("Expected no cell to be lit after the sequence, got %d", lit)
+	}
+
+	// Cells picked during the sequence are ignored
+	if scene.Record(900, 4) || len(scene.Events) != 0 {
+		t.Fatal("Expected a cell picked during the sequence to be ignored")
+	}
+	if scene.Record(1200, 4) || !scene.Record(1500, 0) {
+		t.Fatal("Expected the minigame to end with the last cell")
+	}
+	if len(scene.Events) != 2 || scene.Events[1].Cell != 0 || scene.Events[1].At != 1500 {
+		t.Errorf("Unexpected events %+v", scene.Events)
+	}
+}
+
+func TestMinigameSceneSingleAction(t *testing.T) {
+	for _, kind := range []string{common.MinigameReaction, common.MinigameBar} {
+		scene := NewMinigameScene(common.ChallengePayload{Minigame: &common.MinigamePayload{Kind: kind}})
+		if !scene.Record(1234, 0) || len(scene.Events) != 1 || scene.Events[0].At != 1234 {
+			t.Errorf("%s: expected a single action to end the minigame, got %+v", kind, scene.Events)
+		}
+	}
+}
+
+func TestMemoryCellAt(t *testing.T) {
+	for cell := range GridCol * GridCol {
+		x, y := memoryCellPosition(cell)
+		if found, ok := memoryCellAt(x+MemoryCellSize/2, y+MemoryCellSize/2); !ok || found != cell {
+			t.Errorf("Expected cell %d at its center, got %d (%v)", cell, found, ok)
+		}
+	}
+	if _, ok := memoryCellAt(MemoryGridX+MemoryCellSize+MemoryCellSpacing/2, MemoryGridY); ok {
+		t.Error("Expected no cell between two cells")
+	}
+}
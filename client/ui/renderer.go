@@ -26,6 +26,18 @@ const (
 	// Positions
 	PlayerTurnTextYPos = 150
 	ChallengeQuestionY = 50
+	ChallengeCategoryY = 20
+	ChallengeNoticeY   = 480
+
+	// Nested grid overlays
+	PlayableSubBoardAlpha = 60
+	DecidedSubBoardAlpha  = 200
+
+	// Hint overlay, the alpha of each suggested cell is divided by its rank
+	HintAlpha = 150
+
+	// Protected cells overlay
+	ProtectedAlpha = 70
 
 	// Assets
 	FontPath = "font.ttf"
@@ -86,7 +98,7 @@ func RenderWaitingGame(screen *ebiten.Image, waitingMenu *WaitingMenu) {
 }
 
 // Render the game.
-func RenderGame(screen *ebiten.Image, grid *Grid, myTurn bool) {
+func RenderGame(screen *ebiten.Image, grid *Grid, menu *GameMenu, myTurn bool) {
 	screen.DrawImage(GameMenuImage, nil)
 
 	screenWidth, screenHeight := screen.Bounds().Dx(), screen.Bounds().Dy()
@@ -95,14 +107,32 @@ func RenderGame(screen *ebiten.Image, grid *Grid, myTurn bool) {
 	offsetX := float64(screenWidth-gridWidth) / 2
 	offsetY := float64(screenHeight-gridHeight) / 2
 
-	cellSize := float64(gridWidth) / float64(grid.Col)
+	gridCellSize := grid.CellSize()
+
+	// Symbol images are drawn for the default cell size, scale them to the current grid
+	symbolScale := gridCellSize / float64(cellSize)
 
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(offsetX, offsetY)
 	screen.DrawImage(GridImage, op)
 
-	for x := 0; x < grid.Col; x++ {
-		for y := 0; y < grid.Col; y++ {
+	// Highlight where the next move can be played
+	if grid.IsNested() && myTurn {
+		renderPlayableSubBoards(screen, grid, offsetX, offsetY)
+	}
+
+	// Shade the cells that can not be conquered
+	if len(grid.Protected) > 0 {
+		renderProtected(screen, grid, offsetX, offsetY)
+	}
+
+	// Shade the cells suggested by the last hint
+	if len(grid.Hints) > 0 {
+		renderHints(screen, grid, offsetX, offsetY)
+	}
+
+	for x := 0; x < grid.Cols; x++ {
+		for y := 0; y < grid.Rows; y++ {
 			var img *ebiten.Image
 
 			switch grid.BoardData[x][y] {
@@ -114,12 +144,8 @@ func RenderGame(screen *ebiten.Image, grid *Grid, myTurn bool) {
 
 			if img != nil {
 				opSym := &ebiten.DrawImageOptions{}
-				// Subtract 1 from x and y to align symbols with the grid cells,
-				// because the grid drawing starts at (cellSize, cellSize) rather than (0,0).
-				cellX := (float64(x) - 1) * cellSize
-				cellY := (float64(y) - 1) * cellSize
-
-				opSym.GeoM.Translate(cellX, cellY)
+				opSym.GeoM.Scale(symbolScale, symbolScale)
+				opSym.GeoM.Translate(float64(x)*gridCellSize, float64(y)*gridCellSize)
 				opSym.GeoM.Translate(offsetX, offsetY)
 
 				screen.DrawImage(img, opSym)
@@ -127,6 +153,10 @@ func RenderGame(screen *ebiten.Image, grid *Grid, myTurn bool) {
 		}
 	}
 
+	if grid.IsNested() {
+		renderSubBoards(screen, grid, offsetX, offsetY)
+	}
+
 	if myTurn {
 		msg := "It's goonkin' time"
 
@@ -142,19 +172,99 @@ func RenderGame(screen *ebiten.Image, grid *Grid, myTurn bool) {
 
 		text.Draw(screen, msg, SmallGameFont, op)
 	}
+
+	menu.Draw(screen)
+}
+
+// renderPlayableSubBoards shades the sub-boards the player may play in.
+func renderPlayableSubBoards(screen *ebiten.Image, grid *Grid, offsetX, offsetY float64) {
+	subSize := float64(grid.SubSize()) * grid.CellSize()
+	highlight := color.RGBA{R: 52, G: 152, B: 219, A: PlayableSubBoardAlpha}
+
+	for sx := range grid.SubBoards {
+		for sy := range grid.SubBoards[sx] {
+			if grid.IsSubBoardPlayable(sx, sy) {
+				drawRect(screen, offsetX+float64(sx)*subSize, offsetY+float64(sy)*subSize, subSize, subSize, highlight)
+			}
+		}
+	}
+}
+
+// renderHints shades the suggested cells, the best one being the darkest.
+// Conquests of opponent cells are shaded in orange, other moves in green.
+func renderHints(screen *ebiten.Image, grid *Grid, offsetX, offsetY float64) {
+	size := grid.CellSize()
+
+	for rank, hint := range grid.Hints {
+		shade := color.RGBA{R: 46, G: 204, B: 113, A: uint8(HintAlpha / (rank + 1))}
+		if hint.Conquest {
+			shade = color.RGBA{R: 230, G: 126, B: 34, A: uint8(HintAlpha / (rank + 1))}
+		}
+		drawRect(screen, offsetX+float64(hint.X)*size, offsetY+float64(hint.Y)*size, size, size, shade)
+	}
+}
+
+// renderProtected shades the cells that can not be conquered in gray.
+func renderProtected(screen *ebiten.Image, grid *Grid, offsetX, offsetY float64) {
+	size := grid.CellSize()
+	shade := color.RGBA{R: 127, G: 140, B: 141, A: ProtectedAlpha}
+
+	for _, cell := range grid.Protected {
+		if cell.X < 0 || cell.X >= grid.Cols || cell.Y < 0 || cell.Y >= grid.Rows {
+			continue
+		}
+		drawRect(screen, offsetX+float64(cell.X)*size, offsetY+float64(cell.Y)*size, size, size, shade)
+	}
+}
+
+// renderSubBoards draws the sub-board separators and a big symbol over each won sub-board.
+func renderSubBoards(screen *ebiten.Image, grid *Grid, offsetX, offsetY float64) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(offsetX, offsetY)
+	screen.DrawImage(NestedGridImage, op)
+
+	subSize := float64(grid.SubSize()) * grid.CellSize()
+	symbolScale := subSize / float64(cellSize)
+	veil := color.RGBA{R: 244, G: 246, B: 247, A: DecidedSubBoardAlpha}
+
+	for sx := range grid.SubBoards {
+		for sy := range grid.SubBoards[sx] {
+			var img *ebiten.Image
+
+			switch grid.SubBoards[sx][sy] {
+			case common.P1:
+				img = CrossImage
+			case common.P2:
+				img = CircleImage
+			}
+
+			if img == nil {
+				continue
+			}
+
+			// Fade the small symbols below the winner symbol
+			x, y := offsetX+float64(sx)*subSize, offsetY+float64(sy)*subSize
+			drawRect(screen, x, y, subSize, subSize, veil)
+
+			opSym := &ebiten.DrawImageOptions{}
+			opSym.GeoM.Scale(symbolScale, symbolScale)
+			opSym.GeoM.Translate(x, y)
+			screen.DrawImage(img, opSym)
+		}
+	}
 }
 
 // Render challenge screen.
 func RenderChallenge(screen *ebiten.Image, challenge *ChallengeMenu) {
 	screen.DrawImage(GameMenuImage, nil)
 
+	// Category, above the question
+	if challenge.Category != "" {
+		drawCenteredText(screen, "Category: "+challenge.Category, ChallengeCategoryY, color.Gray{Y: 90})
+	}
+
 	// Question
-	op := &text.DrawOptions{}
-	w, _ := text.Measure(challenge.Question, SmallGameFont, op.LineSpacing)
-	x := float64((WindowWidth - w) / 2)
-	op.GeoM.Translate(x, ChallengeQuestionY)
-	op.ColorScale.ScaleWithColor(color.Black)
-	text.Draw(screen, challenge.Question, SmallGameFont, op)
+	drawCenteredText(screen, challenge.Question, ChallengeQuestionY, color.Black)
 
 	// Timer
 	challenge.Clock.Draw(screen)
@@ -164,6 +274,41 @@ func RenderChallenge(screen *ebiten.Image, challenge *ChallengeMenu) {
 		ansBtn.Draw(screen)
 	}
 
+	// Typed answer
+	if challenge.Field != nil {
+		challenge.Field.Draw(screen)
+		challenge.BtnSubmit.Draw(screen)
+	}
+	if challenge.BtnReset != nil {
+		challenge.BtnReset.Draw(screen)
+	}
+
+	// Notice, at the bottom
+	if challenge.Notice != "" {
+		drawCenteredText(screen, challenge.Notice, ChallengeNoticeY, color.Black)
+	}
+}
+
+// Render minigame screen.
+func RenderMinigame(screen *ebiten.Image, scene *MinigameScene) {
+	screen.DrawImage(GameMenuImage, nil)
+
+	// Instructions
+	drawCenteredText(screen, scene.Question, ChallengeQuestionY, color.Black)
+
+	// Timer
+	scene.Clock.Draw(screen)
+
+	scene.Draw(screen)
+}
+
+// drawCenteredText draws a line of text centered horizontally.
+func drawCenteredText(screen *ebiten.Image, msg string, y float64, clr color.Color) {
+	op := &text.DrawOptions{}
+	w, _ := text.Measure(msg, SmallGameFont, op.LineSpacing)
+	op.GeoM.Translate(float64((WindowWidth-w)/2), y)
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, msg, SmallGameFont, op)
 }
 
 // Render win screen.
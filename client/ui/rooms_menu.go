@@ -1,6 +1,10 @@
 package ui
 
 import (
+	"Goonker/common"
+	"fmt"
+	"slices"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -20,26 +24,130 @@ const (
 	RoomsMenuPlayBotBtnX = ButtonMiddleX + ButtonWidth + ButtonSpacing
 	RoomsMenuPlayBotBtnY = 50.0
 
+	// Bot difficulty button position, below the against bot button
+	RoomsMenuDifficultyBtnX = RoomsMenuPlayBotBtnX
+	RoomsMenuDifficultyBtnY = RoomsMenuPlayBotBtnY + ButtonHeight + 10
+
+	// Bot engine button position, below the difficulty button
+	RoomsMenuEngineBtnX = RoomsMenuPlayBotBtnX
+	RoomsMenuEngineBtnY = RoomsMenuDifficultyBtnY + ButtonHeight + 10
+
 	// Back button position
 	RoomsMenuBackBtnX = ButtonMiddleX - ButtonWidth - ButtonSpacing
 	RoomsMenuBackBtnY = 390.0
 
+	// Side button position, above the back button
+	RoomsMenuSideBtnX = RoomsMenuBackBtnX
+	RoomsMenuSideBtnY = RoomsMenuBackBtnY - ButtonHeight - 10
+
+	// Challenge category button position, above the side button
+	RoomsMenuCategoryBtnX = RoomsMenuBackBtnX
+	RoomsMenuCategoryBtnY = RoomsMenuSideBtnY - ButtonHeight - 10
+
+	// Challenge level button position, above the category button
+	RoomsMenuQuizLevelBtnX = RoomsMenuBackBtnX
+	RoomsMenuQuizLevelBtnY = RoomsMenuCategoryBtnY - ButtonHeight - 10
+
+	// Game mode button position
+	RoomsMenuModeBtnX = ButtonMiddleX + ButtonWidth + ButtonSpacing
+	RoomsMenuModeBtnY = 390.0
+
+	// Hints button position, above the game mode button
+	RoomsMenuHintsBtnX = RoomsMenuModeBtnX
+	RoomsMenuHintsBtnY = RoomsMenuModeBtnY - ButtonHeight - 10
+
+	// Duel button position, above the hints button
+	RoomsMenuDuelBtnX = RoomsMenuModeBtnX
+	RoomsMenuDuelBtnY = RoomsMenuHintsBtnY - ButtonHeight - 10
+
+	// Conquest penalty button position, in the middle column
+	RoomsMenuPenaltyBtnX = ButtonMiddleX
+	RoomsMenuPenaltyBtnY = 390.0
+
+	// Conquest limit button position, above the penalty button
+	RoomsMenuMaxConquestsBtnX = ButtonMiddleX
+	RoomsMenuMaxConquestsBtnY = RoomsMenuPenaltyBtnY - ButtonHeight - 10
+
+	// Protected cells button position, above the conquest limit button
+	RoomsMenuProtectedBtnX = ButtonMiddleX
+	RoomsMenuProtectedBtnY = RoomsMenuMaxConquestsBtnY - ButtonHeight - 10
+
+	// Highest conquest limit offered by the menu, 0 for no limit
+	MaxConquestsLimit = 3
+
 	// Text field
 	RoomsMenuTextFieldX    = (float64(WindowWidth) - RoomsMenuTextFieldW) / 2
 	RoomsMenuTextFieldY    = (float64(WindowHeight)-RoomsMenuTextFieldH)/2 - 100
 	RoomsMenuTextFieldW    = 300
 	RoomsMenuTextFieldH    = 50
 	RoomsMenuTextFieldFont = 14
+
+	// Side playing X and O in turns, in the games started from the menu
+	SideAlternate = "alternate"
+
+	// Category button label when every category is allowed
+	AllCategories = "all"
+
+	// Protected cells presets
+	ProtectedNone    = "none"
+	ProtectedCenter  = "center"
+	ProtectedCorners = "corners"
 )
 
+// protectedPresets lists the presets of the protected cells button
+var protectedPresets = []string{ProtectedNone, ProtectedCenter, ProtectedCorners}
+
+// QuizLevel is a range of challenge difficulties offered by the rooms menu.
+type QuizLevel struct {
+	Name          string
+	MinDifficulty int // 0 for no bound
+	MaxDifficulty int // 0 for no bound
+}
+
+// QuizLevels lists the challenge levels of the level button, the first one allows every challenge.
+var QuizLevels = []QuizLevel{
+	{Name: "any"},
+	{Name: "easy", MinDifficulty: common.ChallengeEasy, MaxDifficulty: common.ChallengeEasy},
+	{Name: "normal", MinDifficulty: common.ChallengeEasy, MaxDifficulty: common.ChallengeMedium},
+	{Name: "hard", MinDifficulty: common.ChallengeMedium, MaxDifficulty: common.ChallengeHard},
+}
+
+// menuSides lists the sides of the side button
+var menuSides = append(slices.Clone(common.Sides), SideAlternate)
+
 // RoomsMenu represents the rooms menu UI.
 type RoomsMenu struct {
 	Rooms         []*Room
 	RoomIndex     int
+	Modes         []string // Modes advertised by the server
+	Mode          string
+	Difficulty    string   // Bot difficulty
+	Engines       []string // Bot engines advertised by the server
+	Engine        string   // Bot engine
+	Hints         bool     // Hints are enabled in created rooms
+	Duel          bool     // Both players answer the challenges of created rooms
+	Side          string   // Side played in created rooms and bot games
+	alternateO    bool     // The next alternated game is played as O
+	Category      string   // Challenge category of created rooms, AllCategories for every one
+	QuizLevel     QuizLevel
+	Penalty       string // Penalty of a failed conquest in created rooms
+	MaxConquests  int    // Conquests per player in created rooms, 0 for no limit
+	Protected     string // Preset of the cells that can not be conquered in created rooms
 	BtnPlayBot    *Button
 	BtnCreateRoom *Button
 	BtnJoinGame   *Button
 	BtnBack       *Button
+	BtnMode       *Button
+	BtnDifficulty *Button
+	BtnEngine     *Button
+	BtnHints      *Button
+	BtnDuel       *Button
+	BtnSide       *Button
+	BtnCategory   *Button
+	BtnQuizLevel  *Button
+	BtnPenalty    *Button
+	BtnConquests  *Button
+	BtnProtected  *Button
 	RoomField     *TextField
 }
 
@@ -52,6 +160,17 @@ func NewRoomsMenu() *RoomsMenu {
 	menu.BtnPlayBot = NewButton(RoomsMenuPlayBotBtnX, RoomsMenuPlayBotBtnY, ButtonWidth, ButtonHeight, "Against Bot", BigFontFace)
 	menu.BtnJoinGame = NewButton(RoomsMenuJoinGameBtnX, RoomsMenuJoinGameBtnY, ButtonWidth, ButtonHeight, "Join Game", BigFontFace)
 	menu.BtnBack = NewButton(RoomsMenuBackBtnX, RoomsMenuBackBtnY, ButtonWidth, ButtonHeight, "Back", BigFontFace)
+	menu.SetModes([]string{common.ModeClassic})
+	menu.SetDifficulty(common.DifficultyMedium)
+	menu.SetEngines(common.Engines)
+	menu.SetHints(true)
+	menu.SetDuel(false)
+	menu.SetSide(menuSides[0])
+	menu.SetCategory(AllCategories)
+	menu.SetQuizLevel(QuizLevels[0])
+	menu.SetPenalty(common.Penalties[0])
+	menu.SetMaxConquests(0)
+	menu.SetProtected(ProtectedNone)
 
 	// Create textfield
 	menu.RoomField = NewTextField(RoomsMenuTextFieldX, RoomsMenuTextFieldY, RoomsMenuTextFieldW, RoomsMenuTextFieldH, RoomsMenuTextFieldFont)
@@ -59,6 +178,265 @@ func NewRoomsMenu() *RoomsMenu {
 	return menu
 }
 
+// SetMode selects the game mode used to create rooms.
+func (m *RoomsMenu) SetMode(mode string) {
+	m.Mode = mode
+	m.BtnMode = NewButton(RoomsMenuModeBtnX, RoomsMenuModeBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Mode: %s", mode), SmallFontFace)
+}
+
+// SetModes updates the available game modes, keeping the selected one if it still exists.
+func (m *RoomsMenu) SetModes(modes []string) {
+	if len(modes) == 0 {
+		return
+	}
+
+	m.Modes = modes
+	for _, mode := range modes {
+		if mode == m.Mode {
+			return
+		}
+	}
+	m.SetMode(modes[0])
+}
+
+// NextMode cycles through the available game modes.
+func (m *RoomsMenu) NextMode() {
+	for i, mode := range m.Modes {
+		if mode == m.Mode {
+			m.SetMode(m.Modes[(i+1)%len(m.Modes)])
+			return
+		}
+	}
+	m.SetMode(m.Modes[0])
+}
+
+// SetDifficulty selects the difficulty of bot games.
+func (m *RoomsMenu) SetDifficulty(difficulty string) {
+	m.Difficulty = difficulty
+	m.BtnDifficulty = NewButton(RoomsMenuDifficultyBtnX, RoomsMenuDifficultyBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Bot: %s", difficulty), SmallFontFace)
+}
+
+// NextDifficulty cycles through the bot difficulties.
+func (m *RoomsMenu) NextDifficulty() {
+	for i, difficulty := range common.Difficulties {
+		if difficulty == m.Difficulty {
+			m.SetDifficulty(common.Difficulties[(i+1)%len(common.Difficulties)])
+			return
+		}
+	}
+	m.SetDifficulty(common.Difficulties[0])
+}
+
+// SetEngine selects the engine of bot games.
+func (m *RoomsMenu) SetEngine(engine string) {
+	m.Engine = engine
+	m.BtnEngine = NewButton(RoomsMenuEngineBtnX, RoomsMenuEngineBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Engine: %s", engine), SmallFontFace)
+}
+
+// SetEngines updates the available bot engines, keeping the selected one if it still exists.
+func (m *RoomsMenu) SetEngines(engines []string) {
+	if len(engines) == 0 {
+		return
+	}
+
+	m.Engines = engines
+	for _, engine := range engines {
+		if engine == m.Engine {
+			return
+		}
+	}
+	m.SetEngine(engines[0])
+}
+
+// NextEngine cycles through the available bot engines.
+func (m *RoomsMenu) NextEngine() {
+	for i, engine := range m.Engines {
+		if engine == m.Engine {
+			m.SetEngine(m.Engines[(i+1)%len(m.Engines)])
+			return
+		}
+	}
+	m.SetEngine(m.Engines[0])
+}
+
+// SetHints selects whether created rooms allow hints.
+func (m *RoomsMenu) SetHints(hints bool) {
+	m.Hints = hints
+	label := "Hints: off"
+	if hints {
+		label = "Hints: on"
+	}
+	m.BtnHints = NewButton(RoomsMenuHintsBtnX, RoomsMenuHintsBtnY, ButtonWidth, ButtonHeight, label, SmallFontFace)
+}
+
+// ToggleHints enables or disables hints in created rooms.
+func (m *RoomsMenu) ToggleHints() {
+	m.SetHints(!m.Hints)
+}
+
+// SetDuel selects whether created rooms are played in duel mode.
+func (m *RoomsMenu) SetDuel(duel bool) {
+	m.Duel = duel
+	label := "Duel: off"
+	if duel {
+		label = "Duel: on"
+	}
+	m.BtnDuel = NewButton(RoomsMenuDuelBtnX, RoomsMenuDuelBtnY, ButtonWidth, ButtonHeight, label, SmallFontFace)
+}
+
+// ToggleDuel enables or disables the duel mode in created rooms.
+func (m *RoomsMenu) ToggleDuel() {
+	m.SetDuel(!m.Duel)
+}
+
+// SetSide selects the side played in created rooms and bot games.
+func (m *RoomsMenu) SetSide(side string) {
+	m.Side = side
+	m.BtnSide = NewButton(RoomsMenuSideBtnX, RoomsMenuSideBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Play as: %s", side), SmallFontFace)
+}
+
+// NextSide cycles through the sides.
+func (m *RoomsMenu) NextSide() {
+	for i, side := range menuSides {
+		if side == m.Side {
+			m.SetSide(menuSides[(i+1)%len(menuSides)])
+			return
+		}
+	}
+	m.SetSide(menuSides[0])
+}
+
+// GameSide returns the side to ask for when starting a game, the alternate side changes at each call.
+func (m *RoomsMenu) GameSide() string {
+	if m.Side != SideAlternate {
+		return m.Side
+	}
+
+	side := common.SideX
+	if m.alternateO {
+		side = common.SideO
+	}
+	m.alternateO = !m.alternateO
+	return side
+}
+
+// SetCategory selects the challenge category of created rooms.
+func (m *RoomsMenu) SetCategory(category string) {
+	m.Category = category
+	m.BtnCategory = NewButton(RoomsMenuCategoryBtnX, RoomsMenuCategoryBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Quiz: %s", category), SmallFontFace)
+}
+
+// NextCategory cycles through all the categories, then each category.
+func (m *RoomsMenu) NextCategory() {
+	categories := append([]string{AllCategories}, common.Categories...)
+	for i, category := range categories {
+		if category == m.Category {
+			m.SetCategory(categories[(i+1)%len(categories)])
+			return
+		}
+	}
+	m.SetCategory(AllCategories)
+}
+
+// SetQuizLevel selects the challenge difficulties of created rooms.
+func (m *RoomsMenu) SetQuizLevel(level QuizLevel) {
+	m.QuizLevel = level
+	m.BtnQuizLevel = NewButton(RoomsMenuQuizLevelBtnX, RoomsMenuQuizLevelBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Quiz level: %s", level.Name), SmallFontFace)
+}
+
+// NextQuizLevel cycles through the challenge levels.
+func (m *RoomsMenu) NextQuizLevel() {
+	for i, level := range QuizLevels {
+		if level == m.QuizLevel {
+			m.SetQuizLevel(QuizLevels[(i+1)%len(QuizLevels)])
+			return
+		}
+	}
+	m.SetQuizLevel(QuizLevels[0])
+}
+
+// ChallengeFilter returns the challenges of the rooms created from the menu.
+func (m *RoomsMenu) ChallengeFilter() common.ChallengeFilter {
+	filter := common.ChallengeFilter{
+		MinDifficulty: m.QuizLevel.MinDifficulty,
+		MaxDifficulty: m.QuizLevel.MaxDifficulty,
+	}
+	if m.Category != AllCategories {
+		filter.Categories = []string{m.Category}
+	}
+	return filter
+}
+
+// SetPenalty selects the penalty of a failed conquest in created rooms.
+func (m *RoomsMenu) SetPenalty(penalty string) {
+	m.Penalty = penalty
+	m.BtnPenalty = NewButton(RoomsMenuPenaltyBtnX, RoomsMenuPenaltyBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Penalty: %s", penalty), SmallFontFace)
+}
+
+// NextPenalty cycles through the penalties of a failed conquest.
+func (m *RoomsMenu) NextPenalty() {
+	i := slices.Index(common.Penalties, m.Penalty)
+	m.SetPenalty(common.Penalties[(i+1)%len(common.Penalties)])
+}
+
+// SetMaxConquests selects the number of conquests per player in created rooms, 0 for no limit.
+func (m *RoomsMenu) SetMaxConquests(limit int) {
+	m.MaxConquests = limit
+	label := "Conquests: any"
+	if limit > 0 {
+		label = fmt.Sprintf("Conquests: %d", limit)
+	}
+	m.BtnConquests = NewButton(RoomsMenuMaxConquestsBtnX, RoomsMenuMaxConquestsBtnY, ButtonWidth, ButtonHeight, label, SmallFontFace)
+}
+
+// NextMaxConquests cycles through no limit, then 1 to MaxConquestsLimit conquests.
+func (m *RoomsMenu) NextMaxConquests() {
+	m.SetMaxConquests((m.MaxConquests + 1) % (MaxConquestsLimit + 1))
+}
+
+// SetProtected selects the preset of the cells that can not be conquered in created rooms.
+func (m *RoomsMenu) SetProtected(preset string) {
+	m.Protected = preset
+	m.BtnProtected = NewButton(RoomsMenuProtectedBtnX, RoomsMenuProtectedBtnY, ButtonWidth, ButtonHeight, fmt.Sprintf("Protected: %s", preset), SmallFontFace)
+}
+
+// NextProtected cycles through the presets of protected cells.
+func (m *RoomsMenu) NextProtected() {
+	i := slices.Index(protectedPresets, m.Protected)
+	m.SetProtected(protectedPresets[(i+1)%len(protectedPresets)])
+}
+
+// ConquestRules returns the conquest rules of the rooms created from the menu.
+// The protected cells are placed on the board of the selected mode.
+func (m *RoomsMenu) ConquestRules() common.ConquestRules {
+	rules := common.ConquestRules{Penalty: m.Penalty, MaxConquests: m.MaxConquests}
+
+	size := common.BoardSize
+	if m.Mode == common.ModeUltimate {
+		size *= common.BoardSize
+	}
+	switch m.Protected {
+	case ProtectedCenter:
+		rules.Protected = []common.Cell{{X: size / 2, Y: size / 2}}
+	case ProtectedCorners:
+		rules.Protected = []common.Cell{{X: 0, Y: 0}, {X: size - 1, Y: 0}, {X: 0, Y: size - 1}, {X: size - 1, Y: size - 1}}
+	}
+	return rules
+}
+
+// JoinRequest returns the request joining roomID, with the room settings of the menu.
+// The side and the bot settings are left to the caller, as they only apply to some rooms.
+func (m *RoomsMenu) JoinRequest(roomID string) common.JoinPayload {
+	return common.JoinPayload{
+		RoomID:     roomID,
+		Mode:       m.Mode,
+		NoHints:    !m.Hints,
+		Duel:       m.Duel,
+		Challenges: m.ChallengeFilter(),
+		Conquests:  m.ConquestRules(),
+	}
+}
+
 // Draw the rooms menu to the screen.
 func (m *RoomsMenu) Draw(screen *ebiten.Image) {
 	screen.DrawImage(RoomsMenuImage, nil)
@@ -66,5 +444,16 @@ func (m *RoomsMenu) Draw(screen *ebiten.Image) {
 	m.BtnCreateRoom.Draw(screen)
 	m.BtnJoinGame.Draw(screen)
 	m.BtnBack.Draw(screen)
+	m.BtnMode.Draw(screen)
+	m.BtnDifficulty.Draw(screen)
+	m.BtnEngine.Draw(screen)
+	m.BtnHints.Draw(screen)
+	m.BtnDuel.Draw(screen)
+	m.BtnSide.Draw(screen)
+	m.BtnCategory.Draw(screen)
+	m.BtnQuizLevel.Draw(screen)
+	m.BtnPenalty.Draw(screen)
+	m.BtnConquests.Draw(screen)
+	m.BtnProtected.Draw(screen)
 	m.RoomField.Draw(screen)
 }
@@ -13,8 +13,6 @@ const (
 	ClockPosX   = (float64(WindowWidth) - ClockWidth) / 2
 	ClockPosY   = 90
 
-	TicksPerSeconds = 60
-
 	RGBDefaultVal = 100
 	MaxRGBAVal    = 255
 )
@@ -27,21 +25,36 @@ func TimerInit() {
 	whiteImg.Fill(color.White)
 }
 
-// Timer handles the logic for a countdown
+// Timer handles the logic for a countdown, ending at a deadline so that it does not drift when frames are late
 type Timer struct {
 	TotalDuration   time.Duration
 	CurrentDuration time.Duration
+	Deadline        time.Time // Local time when the countdown ends
 	IsRunning       bool
 	OnEnd           func()
 }
 
-// NewTimer creates a new timer with a set duration.
+// NewTimer creates a new timer with a set duration, starting now.
 func NewTimer(d time.Duration) *Timer {
-	return &Timer{
-		TotalDuration:   d,
-		CurrentDuration: d,
-		IsRunning:       true,
+	t := NewDeadlineTimer(time.Now().Add(d), d)
+	t.CurrentDuration = d // Full until the first update
+	return t
+}
+
+// NewDeadlineTimer creates a new timer ending at deadline, the bar being full when total is left.
+func NewDeadlineTimer(deadline time.Time, total time.Duration) *Timer {
+	t := &Timer{
+		TotalDuration: total,
+		Deadline:      deadline,
+		IsRunning:     true,
 	}
+	t.CurrentDuration = t.remaining()
+	return t
+}
+
+// remaining returns the time left before the deadline, between 0 and the total duration.
+func (t *Timer) remaining() time.Duration {
+	return max(min(time.Until(t.Deadline), t.TotalDuration), 0)
 }
 
 // Update calculates the remaining time
@@ -50,8 +63,7 @@ func (t *Timer) Update() {
 		return
 	}
 
-	// time.Second / 60 is approximately 16.66ms
-	t.CurrentDuration -= time.Second / TicksPerSeconds
+	t.CurrentDuration = t.remaining()
 
 	if t.CurrentDuration <= 0 {
 		t.CurrentDuration = 0
@@ -18,20 +18,14 @@ func TestTimerLogic(t *testing.T) {
 		t.Errorf("Expected ratio 1.0, got %f", r)
 	}
 
-	// Update (simulate 1 tick)
+	// Update after some time
+	time.Sleep(10 * time.Millisecond)
 	timer.Update()
 
 	if timer.CurrentDuration >= timer.TotalDuration {
 		t.Error("Timer did not decrease duration")
 	}
 
-	// Force expiration
-	timer.CurrentDuration = 5 * time.Millisecond // very small
-	timer.Update()                               // should expire approx
-
-	// Force it to 0
-	timer.CurrentDuration = 0
-
 	// Reset
 	callbackCalled := false
 	timer = NewTimer(time.Second)
@@ -39,11 +33,13 @@ func TestTimerLogic(t *testing.T) {
 		callbackCalled = true
 	}
 
-	// Fast forward
-	// 60 ticks should empty it
-	for i := 0; i < 62; i++ {
-		timer.Update()
+	// Fast forward, frames do not count, only the deadline does
+	timer.Update()
+	if callbackCalled {
+		t.Error("OnEnd callback called before the deadline")
 	}
+	timer.Deadline = time.Now().Add(-time.Millisecond)
+	timer.Update()
 
 	if !callbackCalled {
 		t.Error("OnEnd callback was not called")
@@ -55,3 +51,26 @@ func TestTimerLogic(t *testing.T) {
 		t.Errorf("Duration should be 0, got %v", timer.CurrentDuration)
 	}
 }
+
+func TestDeadlineTimer(t *testing.T) {
+	// A deadline further than the total duration keeps the bar full
+	timer := NewDeadlineTimer(time.Now().Add(2*time.Second), time.Second)
+	if r := timer.Ratio(); r != 1.0 {
+		t.Errorf("Expected ratio 1.0, got %f", r)
+	}
+
+	// The countdown follows the deadline
+	timer = NewDeadlineTimer(time.Now().Add(500*time.Millisecond), time.Second)
+	if r := timer.Ratio(); r > 0.5 || r < 0.4 {
+		t.Errorf("Expected ratio about 0.5, got %f", r)
+	}
+
+	// A deadline already passed ends at the first update
+	ended := false
+	timer = NewDeadlineTimer(time.Now().Add(-time.Second), time.Second)
+	timer.OnEnd = func() { ended = true }
+	timer.Update()
+	if !ended || timer.Ratio() != 0 {
+		t.Error("Expected a timer past its deadline to end")
+	}
+}
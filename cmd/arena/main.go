@@ -0,0 +1,152 @@
+// Command arena runs tournaments between bot engines and difficulties,
+// and reports their results, Elo estimates and thinking times.
+//
+// Games are played in process, conquests are resolved with the challenge
+// accuracy of each difficulty. Random choices are drawn from -seed, but bots
+// thinking within a time budget may still reach different depths between runs.
+//
+// Usage:
+//
+//	arena [-format roundrobin|swiss] [-rounds n] [-games n] [-mode classic] [-seed n] [-think 100ms] [-policy file] [-json file] player...
+//
+// Players are written engine:difficulty, e.g. minimax:perfect or mcts:hard.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+// Tournament formats
+const (
+	FormatRoundRobin = "roundrobin"
+	FormatSwiss      = "swiss"
+)
+
+// Report is the JSON output of a tournament.
+type Report struct {
+	Format    string       `json:"format"`
+	Mode      string       `json:"mode"`
+	Seed      uint64       `json:"seed"`
+	Standings []Standing   `json:"standings"`
+	Games     []GameResult `json:"games"`
+}
+
+func main() {
+	format := flag.String("format", FormatRoundRobin, "tournament format: roundrobin or swiss")
+	rounds := flag.Int("rounds", 3, "number of rounds of a swiss tournament")
+	games := flag.Int("games", 2, "games of each pairing, colors alternate")
+	mode := flag.String("mode", common.ModeClassic, "game mode")
+	seed := flag.Uint64("seed", 1, "seed of the random choices")
+	think := flag.Duration("think", 100*time.Millisecond, "thinking time of the search engines per move")
+	policyPath := flag.String("policy", "", "policy file of the learned engine")
+	jsonPath := flag.String("json", "", "also write the report as JSON to this file ('-' for stdout)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] engine:difficulty...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var policy *logic.Policy
+	if *policyPath != "" {
+		var err error
+		if policy, err = logic.LoadPolicy(*policyPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Read the players
+	var players []Player
+	for _, spec := range flag.Args() {
+		player, err := ParsePlayer(spec, policy)
+		if err != nil {
+			log.Fatal(err)
+		}
+		players = append(players, player)
+	}
+
+	tournament := NewTournament(*mode, players, *seed)
+	tournament.GamesPerPairing = *games
+	tournament.TimeBudget = *think
+	tournament.Policy = policy
+
+	// Play the tournament
+	var err error
+	switch *format {
+	case FormatRoundRobin:
+		err = tournament.RoundRobin()
+	case FormatSwiss:
+		err = tournament.Swiss(*rounds)
+	default:
+		log.Fatalf("Unknown format '%s'", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := Report{
+		Format:    *format,
+		Mode:      *mode,
+		Seed:      *seed,
+		Standings: tournament.Standings(),
+		Games:     tournament.Games(),
+	}
+
+	// The text report goes to stderr when the JSON one uses stdout
+	text := io.Writer(os.Stdout)
+	if *jsonPath == "-" {
+		text = os.Stderr
+	}
+	printReport(text, report)
+
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, report); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// printReport writes the standings as a text table.
+func printReport(w io.Writer, report Report) {
+	fmt.Fprintf(w, "Tournament: %s, mode %s, %d games, seed %d\n\n", report.Format, report.Mode, len(report.Games), report.Seed)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Rank\tPlayer\tGames\tW\tD\tL\tScore\tElo\tThink")
+	for i, standing := range report.Standings {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%d\t%d\t%d\t%.1f\t%.0f\t%.1fms\n",
+			i+1, standing.Player, standing.Games, standing.Wins, standing.Draws, standing.Losses,
+			standing.Score, standing.Elo, standing.AvgThinkMs)
+	}
+	table.Flush()
+}
+
+// writeJSON writes the report to path, or to stdout for "-".
+func writeJSON(path string, report Report) error {
+	out := os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+// Tournament constants
+const (
+	// Games longer than maxPliesFactor times the number of cells are drawn,
+	// failed conquests can otherwise pass the turn forever
+	maxPliesFactor = 4
+
+	// Elo estimation
+	eloBase       = 1500.0
+	eloScale      = 400.0
+	eloIterations = 2000
+	eloStep       = 16.0
+	eloMaxSpread  = 800.0 // Bound for players winning or losing every game
+)
+
+// Player is a bot entered in the tournament, written engine:difficulty.
+type Player struct {
+	Engine     string
+	Difficulty string
+}
+
+// ParsePlayer reads a player written engine:difficulty, policy is the one of the learned engine.
+func ParsePlayer(spec string, policy *logic.Policy) (Player, error) {
+	engine, difficulty, ok := strings.Cut(spec, ":")
+	if !ok {
+		return Player{}, fmt.Errorf("player '%s' must be written engine:difficulty", spec)
+	}
+
+	player := Player{Engine: engine, Difficulty: difficulty}
+	if _, err := logic.NewBotFromConfig(logic.BotConfig{Engine: engine, Difficulty: difficulty, Policy: policy}); err != nil {
+		return Player{}, err
+	}
+	return player, nil
+}
+
+// String returns the player written engine:difficulty.
+func (p Player) String() string {
+	return p.Engine + ":" + p.Difficulty
+}
+
+// GameResult is a game played in the tournament.
+type GameResult struct {
+	Round     int    `json:"round"`
+	X         string `json:"x"`
+	O         string `json:"o"`
+	Result    string `json:"result"` // Record notation, e.g. 1-0
+	Moves     int    `json:"moves"`
+	Conquests int    `json:"conquests"`
+	Forfeit   bool   `json:"forfeit,omitempty"` // The loser played an illegal move
+
+	x, o int // Player indices
+}
+
+// Standing is the summary of a player at the end of the tournament.
+type Standing struct {
+	Player     string  `json:"player"`
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	Draws      int     `json:"draws"`
+	Losses     int     `json:"losses"`
+	Score      float64 `json:"score"`
+	Elo        float64 `json:"elo"`
+	AvgThinkMs float64 `json:"avg_think_ms"`
+}
+
+// Tournament plays bot games in process and keeps their results.
+type Tournament struct {
+	Mode            string
+	Players         []Player
+	GamesPerPairing int           // Games of each pairing, colors alternate
+	TimeBudget      time.Duration // Thinking time of the search engines
+	Policy          *logic.Policy // Policy of the learned engine
+
+	rng       *rand.Rand
+	games     []GameResult
+	thinkTime []time.Duration // Total thinking time of each player
+	moves     []int           // Moves played by each player
+}
+
+// NewTournament creates a tournament whose random choices are drawn from seed.
+func NewTournament(mode string, players []Player, seed uint64) *Tournament {
+	return &Tournament{
+		Mode:            mode,
+		Players:         players,
+		GamesPerPairing: 2,
+		rng:             rand.New(rand.NewPCG(seed, seed)),
+		thinkTime:       make([]time.Duration, len(players)),
+		moves:           make([]int, len(players)),
+	}
+}
+
+// Games returns the games played so far.
+func (t *Tournament) Games() []GameResult {
+	return t.games
+}
+
+// RoundRobin plays every pairing of players once.
+func (t *Tournament) RoundRobin() error {
+	round := 1
+	for a := range t.Players {
+		for b := a + 1; b < len(t.Players); b++ {
+			if err := t.playPairing(round, a, b); err != nil {
+				return err
+			}
+			round++
+		}
+	}
+	return nil
+}
+
+// Swiss plays rounds where players of close scores meet, avoiding rematches when possible.
+// With an odd number of players, the lowest ranked player without a bye sits out and scores a win.
+func (t *Tournament) Swiss(rounds int) error {
+	played := make(map[[2]int]bool)
+	byes := make(map[int]bool)
+
+	for round := 1; round <= rounds; round++ {
+		// Rank by score, the entry order breaks ties
+		scores := t.scores()
+		order := make([]int, len(t.Players))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(scores[b], scores[a])
+		})
+
+		pairs, bye := swissPairs(order, played, byes)
+		if bye >= 0 {
+			byes[bye] = true
+			t.games = append(t.games, GameResult{Round: round, X: t.Players[bye].String(), Result: logic.ResultXWins, x: bye, o: -1})
+		}
+
+		for _, pair := range pairs {
+			played[pair] = true
+			played[[2]int{pair[1], pair[0]}] = true
+			if err := t.playPairing(round, pair[0], pair[1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// swissPairs pairs the players of order, ranked best first, with the next player they have not played.
+// It returns the pairs and the player with a bye, -1 if none.
+func swissPairs(order []int, played map[[2]int]bool, byes map[int]bool) ([][2]int, int) {
+	remaining := slices.Clone(order)
+
+	// The lowest ranked player without a bye sits out
+	bye := -1
+	if len(remaining)%2 == 1 {
+		index := len(remaining) - 1
+		for i := len(remaining) - 1; i >= 0; i-- {
+			if !byes[remaining[i]] {
+				index = i
+				break
+			}
+		}
+		bye = remaining[index]
+		remaining = slices.Delete(remaining, index, index+1)
+	}
+
+	var pairs [][2]int
+	for len(remaining) > 0 {
+		first := remaining[0]
+
+		// Closest opponent not played yet, or the closest one if all were played
+		opponent := 1
+		for i := 1; i < len(remaining); i++ {
+			if !played[[2]int{first, remaining[i]}] {
+				opponent = i
+				break
+			}
+		}
+
+		pairs = append(pairs, [2]int{first, remaining[opponent]})
+		remaining = slices.Delete(remaining, opponent, opponent+1)
+		remaining = remaining[1:]
+	}
+	return pairs, bye
+}
+
+// playPairing plays the games of a pairing, alternating who plays X.
+func (t *Tournament) playPairing(round, a, b int) error {
+	for game := range t.GamesPerPairing {
+		x, o := a, b
+		if game%2 == 1 {
+			x, o = b, a
+		}
+
+		result, err := t.playGame(round, x, o)
+		if err != nil {
+			return err
+		}
+		t.games = append(t.games, result)
+	}
+	return nil
+}
+
+// playGame plays a game between two fresh bots, conquests are resolved with the accuracy of their difficulty.
+func (t *Tournament) playGame(round, x, o int) (GameResult, error) {
+	game, err := logic.NewRules(t.Mode)
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	bots := make(map[common.PlayerID]logic.Bot)
+	indices := map[common.PlayerID]int{common.P1: x, common.P2: o}
+	for player, index := range indices {
+		bots[player], err = logic.NewBotFromConfig(logic.BotConfig{
+			Engine:     t.Players[index].Engine,
+			Difficulty: t.Players[index].Difficulty,
+			TimeBudget: t.TimeBudget,
+			Policy:     t.Policy,
+			Rand:       rand.New(rand.NewPCG(t.rng.Uint64(), t.rng.Uint64())),
+		})
+		if err != nil {
+			return GameResult{}, err
+		}
+	}
+
+	result := GameResult{Round: round, X: t.Players[x].String(), O: t.Players[o].String(), x: x, o: o}
+	state := game.State()
+	maxPlies := maxPliesFactor * state.Width * state.Height
+
+	for result.Moves < maxPlies {
+		if _, over := game.Outcome(); over {
+			break
+		}
+
+		player := game.CurrentTurn()
+		index := indices[player]
+
+		start := time.Now()
+		move := bots[player].NextMove(context.Background(), game)
+		t.thinkTime[index] += time.Since(start)
+		t.moves[index]++
+
+		action := logic.Action{Move: move, Conquest: game.ShouldTriggerChallenge(player, move.X, move.Y)}
+		won := false
+		if action.Conquest {
+			result.Conquests++
+			won = t.rng.Float64() < logic.BotAccuracy[t.Players[index].Difficulty]
+		}
+
+		if err := logic.PlayAction(game, player, action, won); err != nil {
+			// An illegal move loses the game
+			result.Forfeit = true
+			result.Result = logic.ResultXWins
+			if player == common.P1 {
+				result.Result = logic.ResultOWins
+			}
+			return result, nil
+		}
+		result.Moves++
+	}
+
+	winner, over := game.Outcome()
+	switch {
+	case over && winner == common.P1:
+		result.Result = logic.ResultXWins
+	case over && winner == common.P2:
+		result.Result = logic.ResultOWins
+	default:
+		result.Result = logic.ResultDraw
+	}
+	return result, nil
+}
+
+// points returns the points of X and O in a game.
+func (g GameResult) points() (float64, float64) {
+	switch g.Result {
+	case logic.ResultXWins:
+		return 1, 0
+	case logic.ResultOWins:
+		return 0, 1
+	}
+	return 0.5, 0.5
+}
+
+// scores returns the points of each player, byes included.
+func (t *Tournament) scores() []float64 {
+	scores := make([]float64, len(t.Players))
+	for _, game := range t.games {
+		px, po := game.points()
+		scores[game.x] += px
+		if game.o >= 0 {
+			scores[game.o] += po
+		}
+	}
+	return scores
+}
+
+// Standings returns the summary of every player, best first.
+func (t *Tournament) Standings() []Standing {
+	elo := EstimateElo(len(t.Players), t.games)
+	standings := make([]Standing, len(t.Players))
+	for i, player := range t.Players {
+		standings[i] = Standing{Player: player.String(), Elo: math.Round(elo[i])}
+		if t.moves[i] > 0 {
+			standings[i].AvgThinkMs = float64(t.thinkTime[i].Microseconds()) / 1000 / float64(t.moves[i])
+		}
+	}
+
+	for _, game := range t.games {
+		px, po := game.points()
+		standings[game.x].addGame(px)
+		if game.o >= 0 {
+			standings[game.o].addGame(po)
+		}
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		if a.Score != b.Score {
+			return cmp.Compare(b.Score, a.Score)
+		}
+		return cmp.Compare(b.Elo, a.Elo)
+	})
+	return standings
+}
+
+// addGame counts a game where the player scored points.
+func (s *Standing) addGame(points float64) {
+	s.Games++
+	s.Score += points
+	switch points {
+	case 1:
+		s.Wins++
+	case 0:
+		s.Losses++
+	default:
+		s.Draws++
+	}
+}
+
+// EstimateElo fits Elo ratings to the games of n players, byes excluded.
+// Ratings are averaged at eloBase and kept within eloMaxSpread of it.
+func EstimateElo(n int, games []GameResult) []float64 {
+	ratings := make([]float64, n)
+	for i := range ratings {
+		ratings[i] = eloBase
+	}
+
+	for range eloIterations {
+		delta := make([]float64, n)
+		count := make([]int, n)
+		for _, game := range games {
+			if game.o < 0 {
+				continue
+			}
+			px, _ := game.points()
+			expected := 1 / (1 + math.Pow(10, (ratings[game.o]-ratings[game.x])/eloScale))
+			delta[game.x] += px - expected
+			delta[game.o] -= px - expected
+			count[game.x]++
+			count[game.o]++
+		}
+
+		mean := 0.0
+		for i := range ratings {
+			if count[i] > 0 {
+				ratings[i] += eloStep * delta[i] / float64(count[i]) * eloScale / 100
+			}
+			mean += ratings[i]
+		}
+
+		// Keep the average at the base rating
+		shift := eloBase - mean/float64(n)
+		for i := range ratings {
+			ratings[i] = max(eloBase-eloMaxSpread, min(eloBase+eloMaxSpread, ratings[i]+shift))
+		}
+	}
+	return ratings
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+// Input formats of the converter
+const (
+	FormatCSV     = "csv"
+	FormatOpenTDB = "opentdb"
+)
+
+// CSV columns, the header names them in any order
+const (
+	ColumnQuestion   = "question"
+	ColumnAnswer     = "answer" // Correct answer
+	ColumnWrong      = "wrong"  // Prefix of the wrong answer columns, e.g. wrong1, wrong2
+	ColumnCategory   = "category"
+	ColumnDifficulty = "difficulty"
+	ColumnTags       = "tags" // Separated by tagSeparator
+	ColumnLanguage   = "language"
+
+	tagSeparator = ";"
+)
+
+// Error messages
+var (
+	ErrUnknownFormat = errors.New("unknown input format")
+	ErrMissingColumn = errors.New("missing CSV column")
+)
+
+// Open Trivia DB true/false questions
+const (
+	openTDBBoolean = "boolean"
+	openTDBTrue    = "True"
+)
+
+// openTDBDifficulties maps the difficulties of Open Trivia DB to challenge difficulties.
+var openTDBDifficulties = map[string]int{
+	"easy":   common.ChallengeEasy,
+	"medium": common.ChallengeMedium,
+	"hard":   common.ChallengeHard,
+}
+
+// openTDBCategories maps the categories of Open Trivia DB, or their prefix before ':', to challenge categories.
+// The other categories become culture.
+var openTDBCategories = map[string]string{
+	"Geography":            common.CategoryGeography,
+	"History":              common.CategoryHistory,
+	"Politics":             common.CategoryHistory,
+	"Science & Nature":     common.CategoryScience,
+	"Animals":              common.CategoryScience,
+	"Science: Mathematics": common.CategoryMath,
+	"Science: Computers":   common.CategoryTechnology,
+	"Science: Gadgets":     common.CategoryTechnology,
+	"Vehicles":             common.CategoryTechnology,
+}
+
+// openTDBResponse is the body of the Open Trivia DB API, with its default HTML encoding.
+type openTDBResponse struct {
+	Results []struct {
+		Type             string   `json:"type"` // multiple or boolean
+		Category         string   `json:"category"`
+		Difficulty       string   `json:"difficulty"`
+		Question         string   `json:"question"`
+		CorrectAnswer    string   `json:"correct_answer"`
+		IncorrectAnswers []string `json:"incorrect_answers"`
+	} `json:"results"`
+}
+
+// Convert reads challenges in an input format, the correct answer being the first choice.
+func Convert(format string, r io.Reader) ([]logic.Challenge, error) {
+	switch format {
+	case FormatCSV:
+		return convertCSV(r)
+	case FormatOpenTDB:
+		return convertOpenTDB(r)
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrUnknownFormat, format)
+}
+
+// convertCSV reads a CSV file whose header names the columns.
+func convertCSV(r io.Reader) ([]logic.Challenge, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	var wrong []int
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if strings.HasPrefix(name, ColumnWrong) {
+			wrong = append(wrong, i)
+		} else {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{ColumnQuestion, ColumnAnswer} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: '%s'", ErrMissingColumn, name)
+		}
+	}
+
+	var challenges []logic.Challenge
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		challenge := logic.Challenge{
+			Type:       common.ChallengeChoice,
+			Question:   field(ColumnQuestion),
+			Answers:    []string{field(ColumnAnswer)},
+			Category:   strings.ToLower(field(ColumnCategory)),
+			Difficulty: logic.DefaultChallengeDifficulty,
+			Language:   logic.DefaultChallengeLanguage,
+		}
+		if language := field(ColumnLanguage); language != "" {
+			challenge.Language = language
+		}
+		for _, i := range wrong {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				challenge.Answers = append(challenge.Answers, strings.TrimSpace(record[i]))
+			}
+		}
+		if difficulty := field(ColumnDifficulty); difficulty != "" {
+			if challenge.Difficulty, err = strconv.Atoi(difficulty); err != nil {
+				return nil, fmt.Errorf("line %d: invalid difficulty '%s'", line, difficulty)
+			}
+		}
+		if tags := field(ColumnTags); tags != "" {
+			for _, tag := range strings.Split(tags, tagSeparator) {
+				challenge.Tags = append(challenge.Tags, strings.TrimSpace(tag))
+			}
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges, nil
+}
+
+// convertOpenTDB reads a response of the Open Trivia DB API.
+func convertOpenTDB(r io.Reader) ([]logic.Challenge, error) {
+	var response openTDBResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode Open Trivia DB response: %w", err)
+	}
+
+	challenges := make([]logic.Challenge, 0, len(response.Results))
+	for _, result := range response.Results {
+		challenge := logic.Challenge{
+			Type:       common.ChallengeChoice,
+			Question:   html.UnescapeString(result.Question),
+			Answers:    []string{html.UnescapeString(result.CorrectAnswer)},
+			Category:   openTDBCategory(result.Category),
+			Difficulty: logic.DefaultChallengeDifficulty,
+			Tags:       []string{result.Category},
+			Language:   logic.DefaultChallengeLanguage,
+		}
+		if difficulty, ok := openTDBDifficulties[result.Difficulty]; ok {
+			challenge.Difficulty = difficulty
+		}
+
+		if result.Type == openTDBBoolean {
+			challenge.Type = common.ChallengeTrueFalse
+			challenge.Correct = result.CorrectAnswer == openTDBTrue
+			challenge.Answers = slices.Clone(logic.TrueFalseAnswers)
+			if !challenge.Correct {
+				challenge.AnswerKey = 1
+			}
+		} else {
+			for _, answer := range result.IncorrectAnswers {
+				challenge.Answers = append(challenge.Answers, html.UnescapeString(answer))
+			}
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges, nil
+}
+
+// openTDBCategory returns the challenge category of an Open Trivia DB category.
+func openTDBCategory(category string) string {
+	if mapped, ok := openTDBCategories[category]; ok {
+		return mapped
+	}
+	prefix, _, _ := strings.Cut(category, ":")
+	if mapped, ok := openTDBCategories[prefix]; ok {
+		return mapped
+	}
+	return common.CategoryCulture
+}
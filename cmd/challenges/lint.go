@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+// Lint defaults
+const (
+	// Questions at least this similar are reported as near duplicates, 1 being identical
+	DefaultSimilarity = 0.85
+
+	// A correct answer this many times longer or shorter than every other answer leaks the answer
+	DefaultImbalance = 1.5
+
+	// Length differences below minImbalanceChars are never reported, e.g. "8" and "10"
+	minImbalanceChars = 4
+)
+
+// Severities of the issues
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in a challenge.
+type Issue struct {
+	File     string
+	Index    int // 1 based index of the challenge in its file
+	Severity string
+	Message  string
+}
+
+// String returns the issue as file:index: severity: message.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Index, i.Severity, i.Message)
+}
+
+// lintedChallenge is a challenge and its position in the linted packs.
+type lintedChallenge struct {
+	logic.Challenge
+	file       string
+	index      int
+	normalized string // Question compared for duplicates
+}
+
+// Linter checks challenge packs, alone and against each other.
+type Linter struct {
+	Similarity float64
+	Imbalance  float64
+
+	challenges []lintedChallenge
+	issues     []Issue
+}
+
+// NewLinter creates a linter with the default thresholds.
+func NewLinter() *Linter {
+	return &Linter{Similarity: DefaultSimilarity, Imbalance: DefaultImbalance}
+}
+
+// AddPack checks the challenges of a pack and keeps them for the duplicate checks.
+func (l *Linter) AddPack(file string, data []byte) error {
+	challenges, err := logic.DecodeChallengePack(file, data)
+	if err != nil {
+		return err
+	}
+
+	for i, challenge := range challenges {
+		linted := lintedChallenge{Challenge: challenge, file: file, index: i + 1, normalized: normalizeQuestion(challenge.Question)}
+		if err := challenge.Validate(); err != nil {
+			l.report(linted, SeverityError, err.Error())
+		}
+		l.checkAnswers(linted)
+		l.challenges = append(l.challenges, linted)
+	}
+	return nil
+}
+
+// Finish runs the checks between packs and returns every issue found, the duplicates last.
+func (l *Linter) Finish() []Issue {
+	for i, a := range l.challenges {
+		for _, b := range l.challenges[:i] {
+			if a.normalized == b.normalized {
+				l.report(a, SeverityError, fmt.Sprintf("duplicate of %s:%d", b.file, b.index))
+				break
+			}
+			if similarity(a.normalized, b.normalized) >= l.Similarity {
+				l.report(a, SeverityWarning, fmt.Sprintf("near duplicate of %s:%d %q", b.file, b.index, b.Question))
+				break
+			}
+		}
+	}
+	return l.issues
+}
+
+// Challenges returns the number of challenges checked.
+func (l *Linter) Challenges() int {
+	return len(l.challenges)
+}
+
+// checkAnswers reports repeated answers and a correct choice standing out by its length.
+func (l *Linter) checkAnswers(c lintedChallenge) {
+	seen := make(map[string]bool)
+	for _, answer := range c.Answers {
+		key := strings.ToLower(strings.TrimSpace(answer))
+		if seen[key] {
+			l.report(c, SeverityWarning, fmt.Sprintf("answer %q appears twice", answer))
+		}
+		seen[key] = true
+	}
+
+	// Only the choices are shown to the players, the true/false ones are alike
+	if c.Type != common.ChallengeChoice || c.AnswerKey < 0 || c.AnswerKey >= len(c.Answers) || len(c.Answers) < logic.MinChallengeAnswers {
+		return
+	}
+
+	correct := len([]rune(c.Answers[c.AnswerKey]))
+	longest, shortest := 0, -1
+	for i, answer := range c.Answers {
+		if i == c.AnswerKey {
+			continue
+		}
+		length := len([]rune(answer))
+		longest = max(longest, length)
+		if shortest < 0 || length < shortest {
+			shortest = length
+		}
+	}
+
+	switch {
+	case correct-longest >= minImbalanceChars && float64(correct) >= l.Imbalance*float64(longest):
+		l.report(c, SeverityWarning, "the correct answer is much longer than the others")
+	case shortest-correct >= minImbalanceChars && float64(shortest) >= l.Imbalance*float64(correct):
+		l.report(c, SeverityWarning, "the correct answer is much shorter than the others")
+	}
+}
+
+// report records an issue of a challenge.
+func (l *Linter) report(c lintedChallenge, severity, message string) {
+	l.issues = append(l.issues, Issue{File: c.file, Index: c.index, Severity: severity, Message: message})
+}
+
+// normalizeQuestion lowers a question and keeps its words only, so that punctuation and spacing never hide a duplicate.
+func normalizeQuestion(question string) string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// similarity returns 1 minus the edit distance of two strings divided by the longest length.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of rune insertions, deletions and substitutions turning a into b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
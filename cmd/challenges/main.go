@@ -0,0 +1,183 @@
+// Command challenges checks challenge packs and converts question sets from
+// other formats into packs.
+//
+// The lint subcommand reports invalid challenges, duplicated and nearly
+// duplicated questions across every given pack, and correct answers whose
+// length gives them away. It exits with status 1 when errors are found, or
+// warnings with -strict.
+//
+// The convert subcommand reads a CSV file or an Open Trivia DB response and
+// writes a pack, in YAML or JSON as given by the extension of -o, or JSON on
+// the standard output. CSV files start with a header naming their columns:
+// question, answer (the correct one), wrong1, wrong2... and optionally
+// category, difficulty, tags (separated by ';') and language.
+//
+// Usage:
+//
+//	challenges lint [-similarity 0.85] [-imbalance 1.5] [-strict] file|dir...
+//	challenges convert -from csv|opentdb [-category name] [-o pack.yaml] file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"Goonker/server/logic"
+)
+
+// Subcommands
+const (
+	CommandLint    = "lint"
+	CommandConvert = "convert"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case CommandLint:
+		lint(os.Args[2:])
+	case CommandConvert:
+		convert(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// usage prints the subcommands.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  %s %s [flags] file|dir...\n", os.Args[0], CommandLint)
+	fmt.Fprintf(os.Stderr, "  %s %s [flags] file\n", os.Args[0], CommandConvert)
+}
+
+// lint checks the packs given as files or directories.
+func lint(args []string) {
+	flags := flag.NewFlagSet(CommandLint, flag.ExitOnError)
+	similarity := flags.Float64("similarity", DefaultSimilarity, "similarity from which questions are near duplicates, 1 being identical")
+	imbalance := flags.Float64("imbalance", DefaultImbalance, "length ratio from which the correct answer stands out")
+	strict := flags.Bool("strict", false, "fail on warnings too")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] file|dir...\n", os.Args[0], CommandLint)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	linter := NewLinter()
+	linter.Similarity = *similarity
+	linter.Imbalance = *imbalance
+
+	// Expand the directories into their packs
+	var files []string
+	for _, arg := range flags.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		packs, err := logic.ChallengePackFiles(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, packs...)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := linter.AddPack(file, data); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Report the issues
+	errors, warnings := 0, 0
+	for _, issue := range linter.Finish() {
+		fmt.Println(issue)
+		if issue.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Printf("%d challenges, %d errors, %d warnings\n", linter.Challenges(), errors, warnings)
+
+	if errors > 0 || (*strict && warnings > 0) {
+		os.Exit(1)
+	}
+}
+
+// convert writes a pack from a file of another format.
+func convert(args []string) {
+	flags := flag.NewFlagSet(CommandConvert, flag.ExitOnError)
+	from := flags.String("from", FormatCSV, "input format: csv or opentdb")
+	category := flags.String("category", "", "category of every challenge, replacing the converted one")
+	output := flags.String("o", "", "output pack, .json, .yaml or .yml (JSON on the standard output by default)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] file\n", os.Args[0], CommandConvert)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// Read the challenges
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	challenges, err := Convert(*from, file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for i := range challenges {
+		if *category != "" {
+			challenges[i].Category = *category
+		}
+		if err := challenges[i].Validate(); err != nil {
+			log.Fatalf("%s: challenge %d: %v", flags.Arg(0), i+1, err)
+		}
+	}
+
+	// Write the pack
+	name := *output
+	if name == "" {
+		name = logic.DefaultChallengesFile
+	}
+	data, err := logic.EncodeChallengePack(name, challenges)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d challenges written to %s", len(challenges), *output)
+}
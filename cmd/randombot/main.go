@@ -0,0 +1,54 @@
+// Command randombot is an example external bot: it plays random legal moves,
+// tries a conquest from time to time and answers challenges at random.
+// It speaks the external bot protocol described in server/logic/external.go.
+//
+// Usage:
+//
+//	server -bot "randombot -conquests 0.2"
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"Goonker/server/logic"
+)
+
+func main() {
+	conquestRate := flag.Float64("conquests", 0.1, "probability of trying a conquest when one is possible")
+	flag.Parse()
+
+	var legal, conquests []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		command, args, _ := strings.Cut(scanner.Text(), " ")
+		switch command {
+		case logic.CmdLegal:
+			legal = strings.Fields(args)
+		case logic.CmdConquests:
+			conquests = strings.Fields(args)
+		case logic.CmdGo:
+			cells := legal
+			if len(conquests) > 0 && (len(legal) == 0 || rand.Float64() < *conquestRate) {
+				cells = conquests
+			}
+			if len(cells) == 0 {
+				fmt.Printf("%s no move available\n", logic.ReplyInfo)
+				continue
+			}
+			fmt.Printf("%s %s\n", logic.ReplyMove, cells[rand.IntN(len(cells))])
+		case logic.CmdChallenge:
+			// The options follow, only their number matters here
+			countText, _, _ := strings.Cut(args, " ")
+			count, _ := strconv.Atoi(countText)
+			fmt.Printf("%s %d\n", logic.ReplyAnswer, rand.IntN(max(count, 1)))
+		case logic.CmdQuit:
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Command replay steps through a Goonker game record, printing the board
+// after each move and validating the game against the rules of its mode and room.
+//
+// Usage:
+//
+//	replay [-step] record.gkr
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"Goonker/server/logic"
+)
+
+func main() {
+	step := flag.Bool("step", false, "wait for Enter between moves")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-step] record.gkr\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Read the record
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	record, err := logic.ParseGameRecord(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Print the headers
+	fmt.Printf("Mode:    %s\n", record.Mode)
+	fmt.Printf("X:       %s\n", record.PlayerX)
+	fmt.Printf("O:       %s\n", record.PlayerO)
+	fmt.Printf("Date:    %s\n", record.Date.Format(logic.RecordDateFormat))
+	fmt.Printf("Result:  %s\n", record.Result)
+	if conquests := record.Conquests; conquests.Penalty != "" || conquests.MaxConquests > 0 || len(conquests.Protected) > 0 {
+		fmt.Printf("Rules:   %+v\n", conquests)
+	}
+	fmt.Println()
+
+	// Step through the moves
+	stdin := bufio.NewReader(os.Stdin)
+	_, err = record.Replay(func(i int, move logic.MoveRecord, game logic.Rules) {
+		fmt.Printf("%d. %s\n", i+1, logic.FormatMove(move))
+		game.PrintConsoleBoard()
+		fmt.Println()
+
+		if *step {
+			_, _ = stdin.ReadString('\n')
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Valid record, result %s\n", record.Result)
+}
@@ -0,0 +1,50 @@
+// Command tablebase enumerates the positions of a small board reachable
+// through placements and conquests, solves them and writes the tablebase
+// embedded by the server.
+//
+// Usage:
+//
+//	tablebase [-width 3] [-height 3] [-k 3] [-o server/assets/tablebase.bin]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+func main() {
+	width := flag.Int("width", common.BoardSize, "board width")
+	height := flag.Int("height", common.BoardSize, "board height")
+	winLength := flag.Int("k", common.WinLength, "aligned symbols needed to win")
+	output := flag.String("o", "server/assets/"+logic.TablebaseFile, "output file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	rules := logic.BoardRules{Width: *width, Height: *height, WinLength: *winLength}
+	table, err := logic.GenerateTablebase(rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := table.WriteTo(file); err != nil {
+		file.Close()
+		log.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%dx%d board, k=%d: %d positions written to %s\n", rules.Width, rules.Height, rules.WinLength, table.Positions(), *output)
+}
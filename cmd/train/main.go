@@ -0,0 +1,56 @@
+// Command train teaches a policy to the learned bot engine by self-play
+// with tabular Q-learning, conquests included, and saves it to a file.
+//
+// Usage:
+//
+//	train [-mode classic] [-episodes n] [-resume] [-o policy.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+
+	"Goonker/common"
+	"Goonker/server/logic"
+)
+
+func main() {
+	options := logic.DefaultTrainingOptions
+	mode := flag.String("mode", common.ModeClassic, "game mode")
+	flag.IntVar(&options.Episodes, "episodes", options.Episodes, "self-play games")
+	flag.Float64Var(&options.LearningRate, "rate", options.LearningRate, "learning rate")
+	flag.Float64Var(&options.Discount, "discount", options.Discount, "discount of the next position")
+	flag.Float64Var(&options.Exploration, "explore", options.Exploration, "probability of a random action")
+	flag.Float64Var(&options.ConquestProbability, "conquests", options.ConquestProbability, "probability of winning a conquest, 0 to never try one")
+	seed := flag.Uint64("seed", 1, "seed of the random choices")
+	resume := flag.Bool("resume", false, "continue training the policy of the output file")
+	output := flag.String("o", "policy.json", "output file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var policy *logic.Policy
+	var err error
+	if *resume {
+		policy, err = logic.LoadPolicy(*output)
+	} else {
+		policy, err = logic.NewPolicy(*mode)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := policy.Train(options, rand.New(rand.NewPCG(*seed, *seed))); err != nil {
+		log.Fatal(err)
+	}
+	if err := policy.Save(*output); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%s policy: %d episodes, %d positions written to %s\n", policy.Mode, policy.Episodes, len(policy.Values), *output)
+}
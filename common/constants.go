@@ -5,7 +5,8 @@ type PlayerID byte
 
 const (
 	// Game constants
-	BoardSize     = 3
+	BoardSize     = 3 // Default board width and height
+	WinLength     = 3 // Default number of aligned symbols needed to win
 	ChallengeTime = 8
 
 	// PlayerID constants
@@ -13,3 +14,104 @@ const (
 	P1    PlayerID = 1 // X
 	P2    PlayerID = 2 // O
 )
+
+// Game modes
+const (
+	ModeClassic  = "classic"  // Plain Tic-Tac-Toe
+	ModeUltimate = "ultimate" // 3x3 board of 3x3 sub-boards
+)
+
+// Bot difficulties
+const (
+	DifficultyEasy    = "easy"    // Random moves
+	DifficultyMedium  = "medium"  // Shallow search with frequent blunders
+	DifficultyHard    = "hard"    // Full search with rare blunders
+	DifficultyPerfect = "perfect" // Full search
+)
+
+// Bot engines
+const (
+	EngineMinimax = "minimax" // Alpha-beta search
+	EngineMCTS    = "mcts"    // Monte Carlo Tree Search
+
+	// Bot process driven through the external bot protocol, only available
+	// when the server is started with a bot command
+	EngineExternal = "external"
+
+	// Policy learned by self-play, only available when the server is started with a policy
+	EngineLearned = "learned"
+)
+
+// Sides of the player creating a room, X moves first
+const (
+	SideX      = "x"
+	SideO      = "o"
+	SideRandom = "random" // Drawn when the room is created
+)
+
+// Sides lists the sides a room creator may choose, the first one is the default.
+var Sides = []string{SideX, SideO, SideRandom}
+
+// Penalties of a failed conquest, the attacker always loses the turn
+const (
+	PenaltyTurn     = "turn"     // Nothing more
+	PenaltyCell     = "cell"     // The attacker also loses their last placed cell
+	PenaltyCooldown = "cooldown" // The attacked cell can not be attacked again for a few turns
+)
+
+// Penalties lists the penalties of a failed conquest, the first one is the default.
+var Penalties = []string{PenaltyTurn, PenaltyCell, PenaltyCooldown}
+
+// Challenge categories
+const (
+	CategoryGeography  = "geography"
+	CategoryHistory    = "history"
+	CategoryScience    = "science"
+	CategoryMath       = "math"
+	CategoryTechnology = "technology"
+	CategoryCulture    = "culture" // Art, music and everyday life
+)
+
+// Challenge difficulties, from the easiest
+const (
+	ChallengeEasy   = 1
+	ChallengeMedium = 2
+	ChallengeHard   = 3
+)
+
+// Challenge types, how a challenge is answered
+const (
+	ChallengeChoice    = "choice"     // Pick one of the answers
+	ChallengeTrueFalse = "true_false" // Tell whether the question, a statement, is true
+	ChallengeNumeric   = "numeric"    // Type a number, close enough to the expected one
+	ChallengeText      = "text"       // Type the answer, case, accents and punctuation ignored
+	ChallengeOrder     = "order"      // Put the answers in the expected order
+)
+
+// ChallengeMinigame is the type of the minigames, played instead of a question and never part of challenge packs.
+const ChallengeMinigame = "minigame"
+
+// Minigames, played instead of a question to conquer a cell
+const (
+	MinigameReaction = "reaction" // Click as soon as the signal shows
+	MinigameMemory   = "memory"   // Repeat a sequence of cells
+	MinigameBar      = "bar"      // Stop a moving bar in the target zone
+)
+
+// Minigames lists the minigames.
+var Minigames = []string{MinigameReaction, MinigameMemory, MinigameBar}
+
+// ChallengeTypes lists the challenge types, the first one is the default.
+var ChallengeTypes = []string{ChallengeChoice, ChallengeTrueFalse, ChallengeNumeric, ChallengeText, ChallengeOrder}
+
+// NoAnswer is the answer index sent when no answer was given in time.
+const NoAnswer = -1
+
+// Categories lists the challenge categories.
+var Categories = []string{CategoryGeography, CategoryHistory, CategoryScience, CategoryMath, CategoryTechnology, CategoryCulture}
+
+// Engines lists the built-in bot engines, the first one is the default.
+var Engines = []string{EngineMinimax, EngineMCTS}
+
+// Difficulties lists the bot difficulties, from the weakest to the strongest.
+var Difficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard, DifficultyPerfect}
@@ -1,6 +1,9 @@
 package common
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"math"
+)
 
 // Message types
 const (
@@ -13,6 +16,15 @@ const (
 	MsgGameOver  = "game_over"  // Server -> Client: "Game over, result is X"
 	MsgChallenge = "challenge"  // Server -> Client: "Complete this challenge"
 	MsgAnswer    = "answer"     // Client -> Server: "Answer to the challenge"
+
+	MsgChallengeResult = "challenge_result" // Server -> Client: "Who won the challenge, and the right answer"
+
+	MsgTakeback      = "takeback"       // Client -> Server: "Undo my last move", Server -> Client: "Opponent asks for a takeback"
+	MsgTakebackReply = "takeback_reply" // Client -> Server: "I accept/decline", Server -> Client: "Opponent accepted/declined"
+
+	MsgHint = "hint" // Client -> Server: "What should I play?", Server -> Client: "Ranked candidate moves"
+
+	MsgPing = "ping" // Server -> Client: "What is my clock now?", Client -> Server: "Echo of the ping"
 )
 
 // Packet is the generic message structure for communication.
@@ -23,7 +35,9 @@ type Packet struct {
 
 // GameStartPayload is sent by server to notify game start.
 type GameStartPayload struct {
-	YouAre PlayerID `json:"you_are"` // 1 or 2
+	YouAre    PlayerID      `json:"you_are"`            // 1 or 2
+	Hints     int           `json:"hints"`              // Hints each player may ask for, 0 if disabled in the room
+	Conquests ConquestRules `json:"conquests,omitzero"` // Conquest rules of the room
 }
 
 // ClickPayload is sent by client with (x,y) of clicked cell.
@@ -34,14 +48,54 @@ type ClickPayload struct {
 
 // UpdatePayload is sent by server to sync the board.
 type UpdatePayload struct {
-	Board [BoardSize][BoardSize]PlayerID `json:"board"`
-	Turn  PlayerID                       `json:"turn"` // Whose turn is it?
+	Board  [][]PlayerID `json:"board"`  // Indexed as Board[x][y]
+	Width  int          `json:"width"`  // Number of columns
+	Height int          `json:"height"` // Number of rows
+	Turn   PlayerID     `json:"turn"`   // Whose turn is it?
+
+	// Ultimate mode only
+	SubBoards [][]PlayerID `json:"sub_boards,omitempty"` // Winner of each sub-board
+	ActiveX   int          `json:"active_x"`             // Sub-board to play in, -1 if free
+	ActiveY   int          `json:"active_y"`
 }
 
 // JoinPayload is sent by client to join a room.
 type JoinPayload struct {
 	RoomID string `json:"room_id"`
-	IsBot  bool   `json:"is_bot"` // Whether to play against a bot
+	IsBot  bool   `json:"is_bot"`         // Whether to play against a bot
+	Mode   string `json:"mode,omitempty"` // Game mode when creating the room, classic by default
+
+	// Bot strength and engine in bot games, perfect minimax by default
+	Difficulty string `json:"difficulty,omitempty"`
+	Engine     string `json:"engine,omitempty"`
+
+	NoHints bool   `json:"no_hints,omitempty"` // Disable hints in the new room
+	Side    string `json:"side,omitempty"`     // Side of the room creator, X by default, the bot plays the other one
+	Duel    bool   `json:"duel,omitempty"`     // Conquest challenges are also asked to the defender, ignored in bot games
+
+	Challenges ChallengeFilter `json:"challenges,omitzero"` // Challenges of the new room, all by default
+	Conquests  ConquestRules   `json:"conquests,omitzero"`  // Conquest rules of the new room, the default ones if zero
+}
+
+// ConquestRules configure the conquests of a room, the zero value only makes a failed conquest lose the turn.
+type ConquestRules struct {
+	Penalty      string `json:"penalty,omitempty"`       // One of Penalties, PenaltyTurn by default
+	Cooldown     int    `json:"cooldown,omitempty"`      // Turns of the attacker a cell is safe after a failed conquest, cooldown penalty only
+	MaxConquests int    `json:"max_conquests,omitempty"` // Cells each player may conquer in a game, no limit if 0
+	Protected    []Cell `json:"protected,omitempty"`     // Cells that can never be conquered
+}
+
+// Cell is a cell of the board.
+type Cell struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ChallengeFilter restricts the challenges asked in a room, the zero value allows every challenge.
+type ChallengeFilter struct {
+	Categories    []string `json:"categories,omitempty"`     // Allowed categories, all if empty
+	MinDifficulty int      `json:"min_difficulty,omitempty"` // Lowest difficulty, no bound if 0
+	MaxDifficulty int      `json:"max_difficulty,omitempty"` // Highest difficulty, no bound if 0
 }
 
 // GameOverPayload is sent by server when game ends.
@@ -51,16 +105,108 @@ type GameOverPayload struct {
 
 // RoomsPayload is sent by server to notify available rooms.
 type RoomsPayload struct {
-	Rooms []string `json:"rooms"`
+	Rooms   []string `json:"rooms"`
+	Modes   []string `json:"modes"`   // Game modes a new room can be created with
+	Engines []string `json:"engines"` // Bot engines a bot game can be created with
 }
 
 // ChallengePayload is sent by the server to give the challenge informations
 type ChallengePayload struct {
-	Question string   `json:"question"`
-	Answers  []string `json:"answers"`
+	Type       string   `json:"type,omitempty"` // One of ChallengeTypes, choice by default
+	Question   string   `json:"question"`
+	Answers    []string `json:"answers,omitempty"` // Choices, or items to order, none for numeric and text challenges
+	Category   string   `json:"category,omitempty"`
+	Difficulty int      `json:"difficulty,omitempty"`
+
+	Minigame *MinigamePayload `json:"minigame,omitempty"` // Minigame challenges only, the question tells how to play
+
+	Deadline int64 `json:"deadline"` // Server clock in Unix milliseconds when answers are due
+
+	Duel bool `json:"duel,omitempty"` // Both players answer, the first correct answer wins the cell
 }
 
-// AnswerPayload is sent by the client as a response to the challenge
+// ChallengeResultPayload is sent by the server to both players once a challenge is over.
+type ChallengeResultPayload struct {
+	Attacker PlayerID `json:"attacker"`         // Player who tried to conquer the cell
+	Winner   PlayerID `json:"winner"`           // First player to answer correctly, Empty if nobody did
+	Answer   string   `json:"answer,omitempty"` // Right answer, empty for minigames
+	Duel     bool     `json:"duel,omitempty"`
+}
+
+// MinigamePayload holds the parameters of a minigame, drawn by the server.
+// Durations are in milliseconds since the minigame is shown.
+type MinigamePayload struct {
+	Kind string `json:"kind"` // One of Minigames
+
+	// Reaction: the signal shows after Delay, clicking within Limit after it wins
+	Delay int `json:"delay,omitempty"`
+	Limit int `json:"limit,omitempty"`
+
+	// Memory: cells of a 3x3 grid, indexed x + 3*y, lit one after the other for Step each
+	Sequence []int `json:"sequence,omitempty"`
+	Step     int   `json:"step,omitempty"`
+
+	// Bar: the bar goes from 0 to 1 and back in Period, starting at Phase of it,
+	// stopping it between Target and Target + Width wins
+	Period int     `json:"period,omitempty"`
+	Phase  float64 `json:"phase,omitempty"`
+	Target float64 `json:"target,omitempty"`
+	Width  float64 `json:"width,omitempty"`
+}
+
+// MinigameEvent is an action of the player in a minigame: a click, or a cell picked in memory minigames.
+type MinigameEvent struct {
+	At   int `json:"at"`             // Milliseconds since the minigame was shown, informative as the server times the answer
+	Cell int `json:"cell,omitempty"` // Memory minigames only
+}
+
+// BarPosition returns the position of the bar of a bar minigame at a time, from 0 to 1.
+func (m MinigamePayload) BarPosition(at int) float64 {
+	if m.Period <= 0 {
+		return m.Phase
+	}
+
+	// Triangle wave, going up in the first half of the period
+	cycle := math.Mod(float64(at)/float64(m.Period)+m.Phase, 1)
+	if cycle < 0.5 {
+		return cycle * 2
+	}
+	return 2 - cycle*2
+}
+
+// AnswerPayload is sent by the client as a response to the challenge, with the field of its type.
 type AnswerPayload struct {
-	Answer int `json:"answer"`
+	Answer int      `json:"answer"`           // Index of the chosen answer, NoAnswer if none
+	Number *float64 `json:"number,omitempty"` // Numeric challenges
+	Text   string   `json:"text,omitempty"`   // Text challenges
+	Order  []int    `json:"order,omitempty"`  // Ordering challenges, indices of the answers in the chosen order
+
+	Events []MinigameEvent `json:"events,omitempty"` // Minigames, in the order they happened
+}
+
+// PingPayload is sent by the server to measure the round trip time of the connection,
+// and echoed by the client as soon as it is received.
+type PingPayload struct {
+	Time int64 `json:"time"` // Server clock in Unix milliseconds when sent
+	RTT  int   `json:"rtt"`  // Round trip time measured so far in milliseconds, 0 before the first echo
+}
+
+// TakebackReplyPayload is sent by the client to answer a takeback request,
+// and forwarded by the server to the player who asked.
+type TakebackReplyPayload struct {
+	Accept bool `json:"accept"`
+}
+
+// HintMove is a candidate move of a hint, scored for the player asking.
+type HintMove struct {
+	X        int  `json:"x"`
+	Y        int  `json:"y"`
+	Score    int  `json:"score"`    // Higher is better for the player
+	Conquest bool `json:"conquest"` // The move tries to conquer an opponent cell
+}
+
+// HintPayload is sent by the server with the best moves of the current position.
+type HintPayload struct {
+	Moves     []HintMove `json:"moves"`     // Best first, empty if no hint is available
+	Remaining int        `json:"remaining"` // Hints left to the player in this game
 }
@@ -1,6 +1,8 @@
 package hub
 
 import (
+	"Goonker/common"
+	"Goonker/server/logic"
 	"sync"
 )
 
@@ -10,6 +12,12 @@ type Hub struct {
 	mutex sync.Mutex
 }
 
+// ExternalBotCommand is the command line of the bot process used by the external engine, disabled if empty.
+var ExternalBotCommand []string
+
+// LearnedPolicy is the policy played by the learned engine, disabled if nil.
+var LearnedPolicy *logic.Policy
+
 // Singleton Global Hub
 var GlobalHub = &Hub{
 	rooms: make(map[string]*Room),
@@ -22,30 +30,46 @@ func (h *Hub) GetRoom(roomID string) *Room {
 	return h.rooms[roomID]
 }
 
-// CreateRoom creates a new room if it doesn't exist
-func (h *Hub) CreateRoom(roomID string, isBot bool) (*Room, error) {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
+// CreateRoom creates the room of a join request if it doesn't exist.
+// The room settings of the request (mode, difficulty, engine) only apply when the room is created.
+// The room is created without the hub lock, as starting an external bot may take a while.
+func (h *Hub) CreateRoom(join common.JoinPayload) (*Room, error) {
 	// If the room already exists, return it
-	if room, exists := h.rooms[roomID]; exists {
+	if room := h.GetRoom(join.RoomID); room != nil {
 		return room, nil
 	}
 
 	// Create the room
-	newRoom, err := NewRoom(roomID, isBot)
+	newRoom, err := NewRoom(join)
 	if err != nil {
 		return nil, err
 	}
-	h.rooms[roomID] = newRoom
+
+	// Keep the room created in the meantime by another player
+	h.mutex.Lock()
+	room, exists := h.rooms[join.RoomID]
+	if !exists {
+		h.rooms[join.RoomID] = newRoom
+	}
+	h.mutex.Unlock()
+
+	if exists {
+		newRoom.close()
+		return room, nil
+	}
 	return newRoom, nil
 }
 
-// RemoveRoom deletes a room from the hub
+// RemoveRoom deletes a room from the hub, then closes it without the hub lock.
 func (h *Hub) RemoveRoom(roomID string) {
 	h.mutex.Lock()
-	defer h.mutex.Unlock()
+	room, ok := h.rooms[roomID]
 	delete(h.rooms, roomID)
+	h.mutex.Unlock()
+
+	if ok {
+		room.close()
+	}
 }
 
 // GetAvailableRooms returns a slice of the room IDs
@@ -61,3 +85,30 @@ func (h *Hub) GetAvailableRooms() []string {
 	}
 	return availableRooms
 }
+
+// Engines returns the bot engines available on this server.
+func Engines() []string {
+	engines := append([]string(nil), common.Engines...)
+	if len(ExternalBotCommand) > 0 {
+		engines = append(engines, common.EngineExternal)
+	}
+	if LearnedPolicy != nil {
+		engines = append(engines, common.EngineLearned)
+	}
+	return engines
+}
+
+// newBot creates the bot of a room, external bots fall back to the minimax bot of the same difficulty.
+func newBot(engine, difficulty, mode string) (logic.Bot, error) {
+	switch engine {
+	case common.EngineExternal:
+		fallback, err := logic.NewBot(common.EngineMinimax, difficulty)
+		if err != nil {
+			return nil, err
+		}
+		return logic.NewExternalBot(ExternalBotCommand, mode, fallback)
+	case common.EngineLearned:
+		return logic.NewBotFromConfig(logic.BotConfig{Engine: engine, Difficulty: difficulty, Policy: LearnedPolicy})
+	}
+	return logic.NewBot(engine, difficulty)
+}
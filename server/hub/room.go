@@ -3,11 +3,18 @@ package hub
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"Goonker/common"
 	"Goonker/server/logic"
@@ -22,60 +29,190 @@ const (
 	CloseMessage      = "Goodbye"
 	MaxPlayers        = 2
 	MaxPlayersWithBot = 1
+
+	// Time a bot has to find its move, after its thinking delay
+	BotMoveTimeout = 10 * time.Second
+
+	// Game records
+	BotName              = "Bot"
+	RecordFileExt        = ".gkr"
+	RecordFileTimeFormat = "20060102-150405"
+
+	// Hints
+	HintsPerGame            = 3                      // Hints each player may ask for in a game
+	HintMoves               = 3                      // Candidate moves sent in a hint
+	HintTimeBudget          = 500 * time.Millisecond // Thinking time of a hint
+	HintConquestProbability = 0.5                    // Expected success of the conquests suggested to players
+
+	// Time left to the other player of a duel once a correct answer is received,
+	// so that the answers are ranked by the time they reached the server rather than by the order they are handled
+	DuelSettleTime = 50 * time.Millisecond
+
+	// Latency compensation
+	PingInterval         = 2 * time.Second        // Time between two round trip measurements
+	ChallengeGraceMargin = 100 * time.Millisecond // Grace after the challenge deadline, on top of the time the answers take to arrive
+	MaxChallengeGrace    = time.Second            // Longest grace after the challenge deadline, however slow the connection
+)
+
+// RecordsDir is the directory where finished games are saved, records are disabled if empty.
+var RecordsDir string
+
+// BotThinkDelay is the thinking delay of the bots of new rooms.
+var BotThinkDelay = logic.BotThinkDelay
+
+// ChallengeMix holds the shares of generated challenges and minigames in new rooms.
+var ChallengeMix = logic.ChallengeMix{Generated: logic.DefaultGeneratedChallengeRatio, Minigames: logic.DefaultMinigameRatio}
+
+// Error messages
+var (
+	ErrUnknownSide = errors.New("unknown side")
 )
 
 // Player represents a connected player in the room
 type Player struct {
 	Conn *websocket.Conn
 	ID   common.PlayerID
+	RTT  time.Duration // Smoothed round trip time of the connection, 0 until measured
 }
 
 // Room represents a game room with players and game logic
 type Room struct {
 	ID      string
 	Players map[common.PlayerID]*Player
-	Logic   *logic.GameLogic
+	Mode    string
+	Rules   logic.Rules
 
 	mutex     sync.Mutex
 	IsBotGame bool
 
+	// Player given to the room creator, the other one goes to the second player or the bot
+	Host common.PlayerID
+
+	// Bot
+	Difficulty string
+	Engine     string
+	ThinkDelay time.Duration // Delay before the moves of the bot, for a natural gameplay flow
+	botID      common.PlayerID
+	bot        logic.Bot
+
+	// Cancelled when the room is closed, stops the computations of the bot and of the hints
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// Challenge
-	challengeManager   logic.ChallengeManager
-	challengedMove     common.ClickPayload
-	challengeAnswerKey int
-	challengedPlayer   common.PlayerID
-	challengeTimer     *time.Timer
+	challenges        logic.ChallengeSource
+	challengedMove    common.ClickPayload
+	challenge         *logic.Challenge // Challenge asked to the player, as sent
+	challengeSentAt   time.Time
+	challengeDeadline time.Time // Answers are due at this time, plus the grace of each player
+	challengedPlayer  common.PlayerID
+	challengePlayers  []common.PlayerID // Players asked the challenge, the defender too in duels
+	challengeAnswers  map[common.PlayerID]challengeAnswer
+	challengeTimer    *time.Timer
+	challengeActive   bool
+
+	// Conquest challenges are also asked to the defender, the first correct answer wins the cell
+	Duel bool
+
+	// Player waiting for an answer to a takeback request, Empty if none
+	takebackFrom common.PlayerID
+
+	// Hints
+	HintsEnabled bool
+	hintsUsed    map[common.PlayerID]int
+}
+
+// challengeAnswer is an answer to the pending challenge, graded when received.
+type challengeAnswer struct {
+	correct    bool
+	receivedAt time.Time
 }
 
-// NewRoom creates a new Room instance.
-func NewRoom(id string, isBot bool) (*Room, error) {
-	cm, err := logic.NewChallengeManager()
+// NewRoom creates a new Room instance with the settings of a join request.
+func NewRoom(join common.JoinPayload) (*Room, error) {
+	challenges, err := logic.NewChallengeSource(join.Challenges, ChallengeMix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge source: %w", err)
+	}
+
+	mode := join.Mode
+	if mode == "" {
+		mode = common.ModeClassic
+	}
+
+	rules, err := logic.NewRules(mode)
+	if err != nil {
+		return nil, err
+	}
+	if rules, err = logic.WithConquestRules(rules, join.Conquests); err != nil {
+		return nil, err
+	}
+
+	host, err := hostPlayer(join.Side)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create challenge manager: %w", err)
+		return nil, err
+	}
+
+	room := &Room{
+		ID:           join.RoomID,
+		Players:      make(map[common.PlayerID]*Player),
+		Mode:         mode,
+		Rules:        rules,
+		IsBotGame:    join.IsBot,
+		Host:         host,
+		challenges:   challenges,
+		HintsEnabled: !join.NoHints,
+		hintsUsed:    make(map[common.PlayerID]int),
+		Duel:         join.Duel && !join.IsBot,
+	}
+	room.ctx, room.cancel = context.WithCancel(context.Background())
+
+	if join.IsBot {
+		room.Difficulty = join.Difficulty
+		if room.Difficulty == "" {
+			room.Difficulty = common.DifficultyPerfect
+		}
+		room.Engine = join.Engine
+		if room.Engine == "" {
+			room.Engine = common.EngineMinimax
+		}
+		room.ThinkDelay = BotThinkDelay
+		room.botID = logic.Opponent(host)
+		if room.bot, err = newBot(room.Engine, room.Difficulty, mode); err != nil {
+			room.cancel()
+			return nil, err
+		}
 	}
+	return room, nil
+}
 
-	return &Room{
-		ID:               id,
-		Players:          make(map[common.PlayerID]*Player),
-		Logic:            logic.NewGameLogic(),
-		IsBotGame:        isBot,
-		challengeManager: *cm,
-	}, nil
+// hostPlayer returns the player of the room creator for a side.
+func hostPlayer(side string) (common.PlayerID, error) {
+	switch side {
+	case common.SideX, "":
+		return common.P1, nil
+	case common.SideO:
+		return common.P2, nil
+	case common.SideRandom:
+		return common.PlayerID(rand.IntN(2)) + common.P1, nil
+	}
+	return common.Empty, fmt.Errorf("%w: '%s'", ErrUnknownSide, side)
 }
 
 // AddPlayer assigns an ID (P1/P2) to the connecting player and starts listening.
+// The first player gets the side of the host, the second one the other side.
 func (r *Room) AddPlayer(conn *websocket.Conn) common.PlayerID {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	// Determine the player ID (1 or 2)
 	var pid common.PlayerID
-	if _, ok := r.Players[common.P1]; !ok {
-		pid = common.P1
+	if _, ok := r.Players[r.Host]; !ok {
+		pid = r.Host
 	} else if r.IsBotGame {
-		return common.Empty // Bot game already has player 1
-	} else if _, ok := r.Players[common.P2]; !ok {
-		pid = common.P2
+		return common.Empty // Bot game already has its human player
+	} else if _, ok := r.Players[logic.Opponent(r.Host)]; !ok {
+		pid = logic.Opponent(r.Host)
 	} else {
 		return common.Empty // Room full
 	}
@@ -103,10 +240,15 @@ func (r *Room) IsFull() bool {
 }
 
 // startGame initializes the game and notifies players.
+// A bot playing X makes the opening move.
 func (r *Room) startGame() {
 	log.Printf("Room %s: Starting game", r.ID)
 	r.broadcastGameStart()
 	r.broadcastUpdate()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.startBotTurn_Locked()
 }
 
 // listenPlayer listens to incoming messages from a specific client.
@@ -114,6 +256,11 @@ func (r *Room) startGame() {
 func (r *Room) listenPlayer(pid common.PlayerID, conn *websocket.Conn) {
 	ctx := context.Background()
 
+	// Measure the latency of the player while connected
+	pingCtx, stopPing := context.WithCancel(r.ctx)
+	defer stopPing()
+	go r.pingPlayer(pingCtx, pid, conn)
+
 	// Cleanup triggers on function exit (connection closed or error)
 	defer func() {
 		r.mutex.Lock()
@@ -141,38 +288,35 @@ func (r *Room) listenPlayer(pid common.PlayerID, conn *websocket.Conn) {
 		if err != nil {
 			return
 		}
+		receivedAt := time.Now()
 
 		// Handle Click messages
 		switch packet.Type {
 		case common.MsgClick:
 			var payload common.ClickPayload
 			if err := json.Unmarshal(packet.Data, &payload); err == nil {
-				if r.Logic.ShouldTriggerChallenge(pid, payload.X, payload.Y) {
-					r.challengedMove = payload
-					r.challengedPlayer = pid
-					r.startChallenge(conn)
-				} else {
-					r.handleMove(pid, payload.X, payload.Y)
-				}
+				r.handleClick(pid, payload)
 			}
 		case common.MsgGetRooms:
 			r.sendRooms(conn)
+		case common.MsgTakeback:
+			r.requestTakeback(pid)
+		case common.MsgTakebackReply:
+			var payload common.TakebackReplyPayload
+			if err := json.Unmarshal(packet.Data, &payload); err == nil {
+				r.replyTakeback(pid, payload.Accept)
+			}
+		case common.MsgHint:
+			r.requestHint(pid)
+		case common.MsgPing:
+			var payload common.PingPayload
+			if err := json.Unmarshal(packet.Data, &payload); err == nil {
+				r.recordPing(pid, payload, receivedAt)
+			}
 		case common.MsgAnswer:
 			var payload common.AnswerPayload
 			if err := json.Unmarshal(packet.Data, &payload); err == nil {
-				if r.challengeTimer != nil {
-					r.challengeTimer.Stop()
-				}
-				if payload.Answer == r.challengeAnswerKey {
-					log.Println("Challenge completed successfully")
-					r.Logic.DeleteMove(r.challengedMove.X, r.challengedMove.Y)
-					// Play the move
-					r.handleMove(pid, r.challengedMove.X, r.challengedMove.Y)
-				} else {
-					// Play the move, but no challenge this time
-					r.handleMove(pid, r.challengedMove.X, r.challengedMove.Y)
-					log.Println("Challenge failed")
-				}
+				r.answerChallenge(pid, payload, receivedAt)
 			}
 		default:
 			log.Printf("Room %s: Unknown message type: %s", r.ID, packet.Type)
@@ -180,24 +324,86 @@ func (r *Room) listenPlayer(pid common.PlayerID, conn *websocket.Conn) {
 	}
 }
 
+// pingPlayer sends a ping to the player every PingInterval until ctx is done, the echoes measure the round trip time.
+func (r *Room) pingPlayer(ctx context.Context, pid common.PlayerID, conn *websocket.Conn) {
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+
+	for {
+		r.mutex.Lock()
+		var rtt time.Duration
+		if player, ok := r.Players[pid]; ok {
+			rtt = player.RTT
+		}
+		r.mutex.Unlock()
+
+		r.sendJson(conn, common.MsgPing, common.PingPayload{Time: time.Now().UnixMilli(), RTT: int(rtt.Milliseconds())})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordPing updates the round trip time of pid with the echo of a ping, received at receivedAt.
+func (r *Room) recordPing(pid common.PlayerID, ping common.PingPayload, receivedAt time.Time) {
+	rtt := receivedAt.Sub(time.UnixMilli(ping.Time))
+	if rtt < 0 || rtt > PingInterval {
+		return // Forged or stale echo
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	player, ok := r.Players[pid]
+	if !ok {
+		return
+	}
+
+	// Smooth the measures, so that a single slow packet does not change the grace much
+	if player.RTT == 0 {
+		player.RTT = rtt
+	} else {
+		player.RTT = (3*player.RTT + rtt) / 4
+	}
+}
+
 // sendRooms sends the available rooms to the client.
 func (r *Room) sendRooms(conn *websocket.Conn) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	rooms := GlobalHub.GetAvailableRooms()
-	payload := common.RoomsPayload{Rooms: rooms}
+	payload := common.RoomsPayload{Rooms: rooms, Modes: logic.Modes(), Engines: Engines()}
 	r.sendJson(conn, common.MsgRooms, payload)
 }
 
-// startChallenge starts a challenge for the player.
-func (r *Room) startChallenge(conn *websocket.Conn) {
-	log.Println("Start challenge")
+// handleClick plays a click of pid, starting a challenge for the conquest of an opponent cell.
+// Only the player to move may play or open a challenge, and nobody while a challenge is pending,
+// so that a click re-sent during a challenge does not draw a new question or push the deadline back.
+func (r *Room) handleClick(pid common.PlayerID, move common.ClickPayload) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	if r.challengeActive || pid != r.Rules.CurrentTurn() {
+		log.Printf("Room %s: Click of player %d refused", r.ID, pid)
+		return
+	}
+	if r.Rules.ShouldTriggerChallenge(pid, move.X, move.Y) {
+		r.startChallenge_Locked(pid, move)
+		return
+	}
+	r.handleMove_Locked(pid, logic.Action{Move: logic.Move{X: move.X, Y: move.Y}}, false)
+}
+
+// startChallenge_Locked starts a challenge for the player conquering the cell of move.
+// In duels, the challenge is sent to the defender at the same time.
+func (r *Room) startChallenge_Locked(pid common.PlayerID, move common.ClickPayload) {
+	log.Println("Start challenge")
+
 	// Pick a challenge
-	// Pick a challenge
-	challenge, err := r.challengeManager.PickChallenge()
+	challenge, err := r.challenges.PickChallenge()
 	if err != nil {
 		log.Printf("Failed to pick challenge: %v", err)
 		return
@@ -206,55 +412,392 @@ func (r *Room) startChallenge(conn *websocket.Conn) {
 	// Shuffle the answers
 	challenge.Shuffle()
 
-	// Send the challenge to the player
-	payload := common.ChallengePayload{Question: challenge.Question, Answers: challenge.Answers}
-	r.challengeAnswerKey = challenge.AnswerKey
-	r.sendJson(conn, common.MsgChallenge, payload)
+	r.challengedMove = move
+	r.challengedPlayer = pid
+	r.challengePlayers = []common.PlayerID{pid}
+	if _, ok := r.Players[logic.Opponent(pid)]; ok && r.Duel {
+		r.challengePlayers = append(r.challengePlayers, logic.Opponent(pid))
+	}
+	r.challengeAnswers = make(map[common.PlayerID]challengeAnswer)
+
+	// Send the challenge to the players, with a deadline on the server clock
+	r.challengeDeadline = time.Now().Add(common.ChallengeTime * time.Second)
+	payload := challenge.Payload()
+	payload.Duel = len(r.challengePlayers) > 1
+	payload.Deadline = r.challengeDeadline.UnixMilli()
+	r.challenge = challenge
+	r.challengeActive = true
+	for _, player := range r.challengePlayers {
+		r.sendJson(r.Players[player].Conn, common.MsgChallenge, payload)
+	}
+	r.challengeSentAt = time.Now()
 
-	// Start the challenge timer
-	r.challengeTimer = time.AfterFunc(common.ChallengeTime*time.Second+2, func() {
-		r.handleChallengeTimeout()
+	// Start the challenge timer, waiting for the answers of the slowest connection
+	var grace time.Duration
+	for _, player := range r.challengePlayers {
+		grace = max(grace, challengeGrace(r.Players[player].RTT))
+	}
+	r.challengeTimer = time.AfterFunc(time.Until(r.challengeDeadline)+grace, func() {
+		r.handleChallengeTimeout(challenge)
 	})
 }
 
-// handleChallengeTimeout handles the challenge timeout.
-func (r *Room) handleChallengeTimeout() {
-	log.Println("Challenge time ran out")
-	r.handleMove(r.challengedPlayer, r.challengedMove.X, r.challengedMove.Y)
+// challengeGrace returns the time answers are accepted after the deadline, from the round trip time of the connection.
+// An answer sent at the deadline reaches the server half a round trip later.
+func challengeGrace(rtt time.Duration) time.Duration {
+	return min(rtt/2+ChallengeGraceMargin, MaxChallengeGrace)
+}
+
+// answerInTime checks if an answer received at receivedAt was sent before the deadline, given the round trip time of the connection.
+func answerInTime(receivedAt, deadline time.Time, rtt time.Duration) bool {
+	return !receivedAt.After(deadline.Add(challengeGrace(rtt)))
 }
 
-// handleMove coordinates game logic updates and notifications. Returns true if a challenge must start.
-func (r *Room) handleMove(pid common.PlayerID, x, y int) {
+// answerDelay returns the time a player took to answer a challenge sent at sentAt, without the round trip of the challenge and the answer.
+// The round trip removed is bounded, so that a player delaying the pings gains little time.
+func answerDelay(sentAt, receivedAt time.Time, rtt time.Duration) time.Duration {
+	return max(receivedAt.Sub(sentAt)-min(rtt, MaxChallengeGrace), 0)
+}
+
+// answerChallenge grades the answer of pid to the pending challenge, received at receivedAt.
+// The challenge is over once every player answered, or shortly after a correct answer in duels.
+func (r *Room) answerChallenge(pid common.PlayerID, answer common.AnswerPayload, receivedAt time.Time) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	// Apply the move via pure game logic
-	err := r.Logic.ApplyMove(pid, x, y)
-	if err != nil {
+	if !r.challengeActive || !slices.Contains(r.challengePlayers, pid) {
+		return
+	}
+	if _, answered := r.challengeAnswers[pid]; answered {
+		return
+	}
+
+	var rtt time.Duration
+	if player, ok := r.Players[pid]; ok {
+		rtt = player.RTT
+	}
+	correct := r.challenge.Grade(answer, answerDelay(r.challengeSentAt, receivedAt, rtt))
+	if !answerInTime(receivedAt, r.challengeDeadline, rtt) {
+		log.Printf("Room %s: Answer of player %d received after the deadline", r.ID, pid)
+		correct = false
+	}
+	r.challengeAnswers[pid] = challengeAnswer{correct: correct, receivedAt: receivedAt}
+
+	if len(r.challengeAnswers) == len(r.challengePlayers) {
+		r.resolveChallenge_Locked()
+	} else if correct {
+		// The other answer may have reached the server first and still wait for the mutex
+		challenge := r.challenge
+		r.challengeTimer.Stop()
+		r.challengeTimer = time.AfterFunc(DuelSettleTime, func() {
+			r.handleChallengeTimeout(challenge)
+		})
+	}
+}
+
+// handleChallengeTimeout ends challenge if it is still pending, the players who did not answer fail it.
+func (r *Room) handleChallengeTimeout(challenge *logic.Challenge) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.challengeActive || r.challenge != challenge {
+		return
+	}
+	if len(r.challengeAnswers) < len(r.challengePlayers) {
+		log.Println("Challenge time ran out")
+	}
+	r.resolveChallenge_Locked()
+}
+
+// resolveChallenge_Locked tells the players who won the pending challenge and plays the challenged move.
+// The cell is conquered if the attacker gave the first correct answer, otherwise the attacker loses the turn.
+func (r *Room) resolveChallenge_Locked() {
+	r.challengeTimer.Stop()
+
+	winner := challengeWinner(r.challengeAnswers, logic.Opponent(r.challengedPlayer))
+	result := common.ChallengeResultPayload{
+		Attacker: r.challengedPlayer,
+		Winner:   winner,
+		Answer:   r.challenge.Solution(),
+		Duel:     len(r.challengePlayers) > 1,
+	}
+	for _, p := range r.Players {
+		r.sendJson(p.Conn, common.MsgChallengeResult, result)
+	}
+
+	won := winner == r.challengedPlayer
+	if won {
+		log.Println("Challenge completed successfully")
+	} else {
+		log.Println("Challenge failed")
+	}
+
+	// Play the conquest, the cell only changes hands if it was won
+	action := logic.Action{Move: logic.Move{X: r.challengedMove.X, Y: r.challengedMove.Y}, Conquest: true}
+	r.handleMove_Locked(r.challengedPlayer, action, won)
+}
+
+// challengeWinner returns the player whose correct answer was received first, Empty if no answer is correct.
+// The defender wins the answers received at the same time.
+func challengeWinner(answers map[common.PlayerID]challengeAnswer, defender common.PlayerID) common.PlayerID {
+	winner := common.Empty
+	var first time.Time
+	for pid, answer := range answers {
+		if !answer.correct {
+			continue
+		}
+		if winner == common.Empty || answer.receivedAt.Before(first) || (answer.receivedAt.Equal(first) && pid == defender) {
+			winner, first = pid, answer.receivedAt
+		}
+	}
+	return winner
+}
+
+// handleMove_Locked applies an action and notifies the players, won tells whether a conquest succeeded.
+func (r *Room) handleMove_Locked(pid common.PlayerID, action logic.Action, won bool) {
+	// Any move resolves the pending challenge and cancels a takeback request
+	r.challengeActive = false
+	r.takebackFrom = common.Empty
+
+	// Apply the move via pure game logic, a refused conquest leaves the cell untouched
+	if err := logic.PlayAction(r.Rules, pid, action, won); err != nil {
 		log.Printf("Invalid move from %d: %v", pid, err)
+		return
 	}
 
 	// Send the updated board state to all players
 	r.broadcastUpdate_Locked()
 
 	// Check if game is over, broadcast the result
-	if r.Logic.GameOver {
-		r.broadcastGameOver()
+	_, gameOver := r.Rules.Outcome()
+	if gameOver {
+		r.broadcastGameOver_Locked()
 	}
 
 	// If it's a Bot Game and the game is not over, the bot plays.
-	// Launch the bot in a goroutine to avoid blocking the mutex for too long.
-	if r.IsBotGame && !r.Logic.GameOver && r.Logic.Turn == common.P2 {
-		// Take a snapshot of the current game logic
-		logicSnapshot := r.Logic
-		go func(snapshot *logic.GameLogic) {
-			botX, botY := logic.GetBotMove(snapshot)
-			if botX != logic.InvalidCoord {
-				// Valid move returned
-				r.handleMove(common.P2, botX, botY)
+	if !gameOver {
+		r.startBotTurn_Locked()
+	}
+}
+
+// startBotTurn_Locked makes the bot play if it is its turn.
+// The bot runs in a goroutine to avoid blocking the mutex for too long.
+func (r *Room) startBotTurn_Locked() {
+	if !r.IsBotGame || r.Rules.CurrentTurn() != r.botID {
+		return
+	}
+
+	// Take a snapshot of the current game logic
+	logicSnapshot := r.Rules.Clone()
+	go func(snapshot logic.Rules) {
+		ctx, cancel := context.WithTimeout(r.ctx, r.ThinkDelay+BotMoveTimeout)
+		defer cancel()
+
+		botX, botY := logic.GetBotMove(ctx, r.bot, snapshot, r.ThinkDelay)
+		if botX != logic.InvalidCoord && r.ctx.Err() == nil {
+			// Valid move returned and the room is still open
+			r.playBotMove(botX, botY)
+		}
+	}(logicSnapshot) // Pass a snapshot to avoid race conditions
+}
+
+// playBotMove plays a move of the bot, answering the challenge of a conquest with the accuracy of its difficulty.
+func (r *Room) playBotMove(x, y int) {
+	r.mutex.Lock()
+	conquest := r.Rules.ShouldTriggerChallenge(r.botID, x, y)
+	var challenge *logic.Challenge
+	if conquest {
+		var err error
+		if challenge, err = r.challenges.PickChallenge(); err != nil {
+			log.Printf("Room %s: %v", r.ID, err)
+		}
+	}
+	r.mutex.Unlock()
+
+	// The bot answers without the room lock, an external bot may take a while
+	won := conquest && r.botAnswers(challenge)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if won {
+		log.Printf("Room %s: Bot won the challenge", r.ID)
+	} else if conquest {
+		log.Printf("Room %s: Bot failed the challenge", r.ID)
+	}
+	r.handleMove_Locked(r.botID, logic.Action{Move: logic.Move{X: x, Y: y}, Conquest: conquest}, won)
+}
+
+// botAnswers returns whether the bot answers challenge correctly, nil if none could be picked.
+// Bots answering challenges themselves are asked the ones with choices, the others answer with the accuracy of their difficulty.
+func (r *Room) botAnswers(challenge *logic.Challenge) bool {
+	if challenge == nil {
+		return logic.BotAnswers(r.Difficulty)
+	}
+
+	if answerer, ok := r.bot.(logic.ChallengeAnswerer); ok && challenge.HasChoices() {
+		challenge.Shuffle()
+		ctx, cancel := context.WithTimeout(r.ctx, BotMoveTimeout)
+		defer cancel()
+		answer, err := answerer.AnswerChallenge(ctx, challenge.Question, challenge.Answers)
+		if err == nil {
+			return answer == challenge.AnswerKey
+		}
+	}
+	return logic.BotAnswers(r.Difficulty)
+}
+
+// close stops the computations running for the room and releases its bot.
+func (r *Room) close() {
+	r.cancel()
+	r.closeBot()
+}
+
+// closeBot releases the bot of the room, e.g. stops the process of an external bot.
+func (r *Room) closeBot() {
+	if closer, ok := r.bot.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Room %s: failed to close the bot: %v", r.ID, err)
+		}
+	}
+}
+
+// requestTakeback forwards a takeback request to the opponent of pid.
+// Takebacks are only negotiated between two human players.
+func (r *Room) requestTakeback(pid common.PlayerID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.IsBotGame || r.challengeActive || r.takebackFrom != common.Empty {
+		return
+	}
+	if _, gameOver := r.Rules.Outcome(); gameOver {
+		return
+	}
+
+	// The requester must have a move to take back
+	hasMove := false
+	for _, move := range r.Rules.History() {
+		if move.Player == pid {
+			hasMove = true
+			break
+		}
+	}
+	if !hasMove {
+		return
+	}
+
+	opponent, ok := r.Players[logic.Opponent(pid)]
+	if !ok {
+		return
+	}
+
+	log.Printf("Room %s: Player %d asks for a takeback", r.ID, pid)
+	r.takebackFrom = pid
+	r.sendJson(opponent.Conn, common.MsgTakeback, nil)
+}
+
+// replyTakeback applies the answer of pid to the pending takeback request.
+// On accept, moves are undone until the last move of the requester is reverted.
+func (r *Room) replyTakeback(pid common.PlayerID, accept bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	requester := r.takebackFrom
+	if requester == common.Empty || requester == pid {
+		return
+	}
+	r.takebackFrom = common.Empty
+
+	if accept {
+		log.Printf("Room %s: Takeback accepted", r.ID)
+		for {
+			history := r.Rules.History()
+			if len(history) == 0 {
+				break
 			}
-		}(logicSnapshot) // Pass a snapshot to avoid race conditions
+			if err := r.Rules.Undo(); err != nil {
+				log.Printf("Room %s: Failed to undo: %v", r.ID, err)
+				break
+			}
+			if history[len(history)-1].Player == requester {
+				break
+			}
+		}
+		r.broadcastUpdate_Locked()
+	} else {
+		log.Printf("Room %s: Takeback declined", r.ID)
+	}
+
+	if p, ok := r.Players[requester]; ok {
+		r.sendJson(p.Conn, common.MsgTakebackReply, common.TakebackReplyPayload{Accept: accept})
+	}
+}
+
+// requestHint sends the best moves of the position to pid, analyzed in the background.
+// Hints are only given to the player to move, at most HintsPerGame times per game.
+func (r *Room) requestHint(pid common.PlayerID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	player, ok := r.Players[pid]
+	if !ok {
+		return
+	}
+
+	remaining := r.hintsRemaining(pid)
+	_, gameOver := r.Rules.Outcome()
+	if remaining == 0 || gameOver || r.challengeActive || r.Rules.CurrentTurn() != pid {
+		r.sendJson(player.Conn, common.MsgHint, common.HintPayload{Remaining: remaining})
+		return
+	}
+	r.hintsUsed[pid]++
+
+	snapshot := r.Rules.Clone()
+	go func() {
+		moves := analyzeHint(r.ctx, snapshot)
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		// The hint is refunded if the position changed during the analysis
+		if r.Rules.CurrentTurn() != pid || logic.FormatBoard(r.Rules.State().Board) != logic.FormatBoard(snapshot.State().Board) {
+			r.hintsUsed[pid]--
+			moves = nil
+		}
+
+		if player, ok := r.Players[pid]; ok {
+			log.Printf("Room %s: Hint sent to player %d", r.ID, pid)
+			r.sendJson(player.Conn, common.MsgHint, common.HintPayload{Moves: moves, Remaining: r.hintsRemaining(pid)})
+		}
+	}()
+}
+
+// hintsRemaining returns the number of hints pid may still ask for.
+func (r *Room) hintsRemaining(pid common.PlayerID) int {
+	if !r.HintsEnabled {
+		return 0
+	}
+	return max(HintsPerGame-r.hintsUsed[pid], 0)
+}
+
+// analyzeHint returns the best moves of the player to move in game, none if ctx is done first.
+func analyzeHint(ctx context.Context, game logic.Rules) []common.HintMove {
+	searcher := logic.NewSearcher(logic.SearchOptions{
+		TimeBudget:          HintTimeBudget,
+		ConquestProbability: HintConquestProbability,
+	})
+	scores := searcher.Analyze(ctx, game)
+
+	moves := make([]common.HintMove, 0, HintMoves)
+	for _, score := range scores[:min(len(scores), HintMoves)] {
+		moves = append(moves, common.HintMove{
+			X:        score.Move.X,
+			Y:        score.Move.Y,
+			Score:    score.Score,
+			Conquest: score.Conquest,
+		})
 	}
+	return moves
 }
 
 // broadcastGameStart notifies all players that the game is starting.
@@ -265,7 +808,9 @@ func (r *Room) broadcastGameStart() {
 	// Notify all players that the game is starting
 	for pid, p := range r.Players {
 		payload := common.GameStartPayload{
-			YouAre: pid,
+			YouAre:    pid,
+			Hints:     r.hintsRemaining(pid),
+			Conquests: logic.ConquestRulesOf(r.Rules),
 		}
 		r.sendJson(p.Conn, common.MsgGameStart, payload)
 	}
@@ -281,11 +826,8 @@ func (r *Room) broadcastUpdate() {
 // broadcastUpdate_Locked sends the current game state to all players.
 func (r *Room) broadcastUpdate_Locked() {
 	log.Printf("Room %s: Broadcasting update", r.ID)
-	r.Logic.PrintConsoleBoard()
-	payload := common.UpdatePayload{
-		Board: r.Logic.Board,
-		Turn:  r.Logic.Turn,
-	}
+	r.Rules.PrintConsoleBoard()
+	payload := r.Rules.State()
 
 	// Send the update to all players
 	for _, p := range r.Players {
@@ -293,11 +835,13 @@ func (r *Room) broadcastUpdate_Locked() {
 	}
 }
 
-// broadcastGameOver notifies all players that the game has ended.
-func (r *Room) broadcastGameOver() {
+// broadcastGameOver_Locked notifies all players that the game has ended.
+// The record is saved and the room removed in a goroutine, as file IO and closing an external bot may take a while.
+func (r *Room) broadcastGameOver_Locked() {
 	log.Printf("Room %s: Broadcasting game over", r.ID)
+	winner, _ := r.Rules.Outcome()
 	payload := common.GameOverPayload{
-		Winner: r.Logic.Winner,
+		Winner: winner,
 	}
 
 	// Send the game over to all players
@@ -305,8 +849,55 @@ func (r *Room) broadcastGameOver() {
 		r.sendJson(p.Conn, common.MsgGameOver, payload)
 	}
 
-	// Remove the room
-	GlobalHub.RemoveRoom(r.ID)
+	// Keep a record of the game and remove the room, without the room lock
+	record := r.newRecord_Locked()
+	go func() {
+		r.saveRecord(record)
+		GlobalHub.RemoveRoom(r.ID)
+	}()
+}
+
+// newRecord_Locked builds the record of the game.
+func (r *Room) newRecord_Locked() *logic.GameRecord {
+	names := map[common.PlayerID]string{common.P1: PlayerName(common.P1), common.P2: PlayerName(common.P2)}
+	if r.IsBotGame {
+		names[r.botID] = BotName
+	}
+	return logic.NewGameRecord(r.Mode, r.Rules, names[common.P1], names[common.P2])
+}
+
+// saveRecord writes the game record to RecordsDir, if configured.
+func (r *Room) saveRecord(record *logic.GameRecord) {
+	if RecordsDir == "" {
+		return
+	}
+
+	// Room IDs come from the clients, only keep safe characters for the file name
+	safeID := strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_' {
+			return c
+		}
+		return -1
+	}, r.ID)
+	name := fmt.Sprintf("%s_%s%s", record.Date.Format(RecordFileTimeFormat), safeID, RecordFileExt)
+
+	file, err := os.Create(filepath.Join(RecordsDir, name))
+	if err != nil {
+		log.Printf("Room %s: Failed to save record: %v", r.ID, err)
+		return
+	}
+	defer file.Close()
+
+	if err := record.Encode(file); err != nil {
+		log.Printf("Room %s: Failed to save record: %v", r.ID, err)
+		return
+	}
+	log.Printf("Room %s: Record saved to %s", r.ID, name)
+}
+
+// PlayerName returns the name of a human player in records.
+func PlayerName(pid common.PlayerID) string {
+	return fmt.Sprintf("Player %d", pid)
 }
 
 // sendJson helps to reduce boilerplate and enforce timeouts
@@ -0,0 +1,126 @@
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"Goonker/common"
+)
+
+func TestChallengeWinner(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	testCases := []struct {
+		name    string
+		answers map[common.PlayerID]challengeAnswer
+		winner  common.PlayerID
+	}{
+		{"attacker alone right", map[common.PlayerID]challengeAnswer{common.P1: {correct: true, receivedAt: at(300)}}, common.P1},
+		{"attacker alone wrong", map[common.PlayerID]challengeAnswer{common.P1: {correct: false, receivedAt: at(300)}}, common.Empty},
+		{"attacker first", map[common.PlayerID]challengeAnswer{
+			common.P1: {correct: true, receivedAt: at(300)},
+			common.P2: {correct: true, receivedAt: at(400)},
+		}, common.P1},
+		{"defender first", map[common.PlayerID]challengeAnswer{
+			common.P1: {correct: true, receivedAt: at(400)},
+			common.P2: {correct: true, receivedAt: at(300)},
+		}, common.P2},
+		{"tie goes to the defender", map[common.PlayerID]challengeAnswer{
+			common.P1: {correct: true, receivedAt: at(300)},
+			common.P2: {correct: true, receivedAt: at(300)},
+		}, common.P2},
+		{"both wrong", map[common.PlayerID]challengeAnswer{
+			common.P1: {correct: false, receivedAt: at(300)},
+			common.P2: {correct: false, receivedAt: at(400)},
+		}, common.Empty},
+		{"first answer wrong", map[common.PlayerID]challengeAnswer{
+			common.P1: {correct: false, receivedAt: at(300)},
+			common.P2: {correct: true, receivedAt: at(400)},
+		}, common.P2},
+		{"defender timed out", map[common.PlayerID]challengeAnswer{common.P1: {correct: true, receivedAt: at(900)}}, common.P1},
+		{"attacker timed out", map[common.PlayerID]challengeAnswer{common.P2: {correct: true, receivedAt: at(900)}}, common.P2},
+		{"nobody answered", map[common.PlayerID]challengeAnswer{}, common.Empty},
+	}
+	for _, tc := range testCases {
+		if winner := challengeWinner(tc.answers, common.P2); winner != tc.winner {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.winner, winner)
+		}
+	}
+}
+
+func TestChallengeGrace(t *testing.T) {
+	testCases := []struct {
+		rtt   time.Duration
+		grace time.Duration
+	}{
+		{0, ChallengeGraceMargin},
+		{100 * time.Millisecond, 50*time.Millisecond + ChallengeGraceMargin},
+		{time.Second, 500*time.Millisecond + ChallengeGraceMargin},
+		{5 * time.Second, MaxChallengeGrace},
+	}
+	for _, tc := range testCases {
+		if grace := challengeGrace(tc.rtt); grace != tc.grace {
+			t.Errorf("RTT %v: expected a grace of %v, got %v", tc.rtt, tc.grace, grace)
+		}
+	}
+}
+
+func TestAnswerInTime(t *testing.T) {
+	deadline := time.Now()
+	rtt := 200 * time.Millisecond // Grace of 200ms with the margin
+
+	testCases := []struct {
+		name       string
+		receivedAt time.Time
+		rtt        time.Duration
+		inTime     bool
+	}{
+		{"before the deadline", deadline.Add(-time.Second), rtt, true},
+		{"within the grace", deadline.Add(150 * time.Millisecond), rtt, true},
+		{"at the end of the grace", deadline.Add(200 * time.Millisecond), rtt, true},
+		{"after the grace", deadline.Add(250 * time.Millisecond), rtt, false},
+		{"after the grace of a fast connection", deadline.Add(150 * time.Millisecond), 0, false},
+		{"after the longest grace", deadline.Add(MaxChallengeGrace + time.Millisecond), 10 * time.Second, false},
+	}
+	for _, tc := range testCases {
+		if inTime := answerInTime(tc.receivedAt, deadline, tc.rtt); inTime != tc.inTime {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.inTime, inTime)
+		}
+	}
+}
+
+func TestAnswerDelay(t *testing.T) {
+	sentAt := time.Now()
+	if delay := answerDelay(sentAt, sentAt.Add(time.Second), 200*time.Millisecond); delay != 800*time.Millisecond {
+		t.Errorf("Expected the round trip to be removed, got %v", delay)
+	}
+	if delay := answerDelay(sentAt, sentAt.Add(3*time.Second), 2*time.Second); delay != 3*time.Second-MaxChallengeGrace {
+		t.Errorf("Expected the removed round trip to be bounded, got %v", delay)
+	}
+	if delay := answerDelay(sentAt, sentAt.Add(100*time.Millisecond), 200*time.Millisecond); delay != 0 {
+		t.Errorf("Expected a delay of 0 at least, got %v", delay)
+	}
+}
+
+func TestRecordPing(t *testing.T) {
+	r := &Room{Players: map[common.PlayerID]*Player{common.P1: {}}}
+	sentAt := time.UnixMilli(time.Now().UnixMilli())
+	ping := common.PingPayload{Time: sentAt.UnixMilli()}
+
+	r.recordPing(common.P1, ping, sentAt.Add(100*time.Millisecond))
+	if rtt := r.Players[common.P1].RTT; rtt != 100*time.Millisecond {
+		t.Errorf("Expected the first measure to be kept, got %v", rtt)
+	}
+	r.recordPing(common.P1, ping, sentAt.Add(500*time.Millisecond))
+	if rtt := r.Players[common.P1].RTT; rtt != 200*time.Millisecond {
+		t.Errorf("Expected the measures to be smoothed, got %v", rtt)
+	}
+
+	// Forged and stale echoes are ignored
+	r.recordPing(common.P1, ping, sentAt.Add(-time.Second))
+	r.recordPing(common.P1, ping, sentAt.Add(PingInterval+time.Millisecond))
+	if rtt := r.Players[common.P1].RTT; rtt != 200*time.Millisecond {
+		t.Errorf("Expected invalid echoes to be ignored, got %v", rtt)
+	}
+}
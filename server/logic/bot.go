@@ -2,107 +2,247 @@ package logic
 
 import (
 	"Goonker/common"
-	"math"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
 	"time"
 )
 
 // Constants for bot behavior
 const (
-	BotThinkDelay = 500 * time.Millisecond
+	BotThinkDelay = 500 * time.Millisecond // Default delay before the moves of the server bots
 	InvalidCoord  = -1
-	MaxDepth      = (common.BoardSize * common.BoardSize) + 1
+
+	// Score of a win, reduced by the number of moves needed to reach it
+	WinScore = 1_000_000
+
+	// Difficulty tuning
+	MediumSearchDepth = 2   // Plies searched by the medium bot
+	MediumBlunderRate = 0.3 // Probability of a random move for the medium bot
+	HardBlunderRate   = 0.1 // Probability of a random move for the hard bot
+
+	// Playouts of the MCTS bots, the perfect one uses the time budget instead
+	MediumMCTSIterations = 100
+	HardMCTSIterations   = 2000
 )
 
-// GetBotMove implements the minimax algorithm to find the best move for the bot.
-func GetBotMove(logic *GameLogic) (int, int) {
-	// Simulate "thinking" time for natural gameplay flow
-	time.Sleep(BotThinkDelay)
+// BotAccuracy is the probability that a bot of each difficulty answers a challenge correctly.
+// Bots expect to win their conquests with the same probability.
+var BotAccuracy = map[string]float64{
+	common.DifficultyEasy:    0.25,
+	common.DifficultyMedium:  0.5,
+	common.DifficultyHard:    0.75,
+	common.DifficultyPerfect: 0.9,
+}
 
-	// Create a copy of the board to evaluate moves
-	currentBoard := logic.Board
+// Error messages
+var (
+	ErrUnknownDifficulty = errors.New("unknown bot difficulty")
+	ErrUnknownEngine     = errors.New("unknown bot engine")
+)
 
-	// Initialize variables to track the best move
-	bestScore := math.Inf(-1)
-	moveX, moveY := InvalidCoord, InvalidCoord
+// Bot chooses the moves of a computer player.
+type Bot interface {
+	// NextMove returns a move for the player to move, or InvalidCoord when there is none.
+	// The bot stops thinking when ctx is done, the move is then only legal.
+	NextMove(ctx context.Context, game Rules) Move
+}
 
-	// Iterate through all possible moves
-	for x := range common.BoardSize {
-		for y := range common.BoardSize {
-			if currentBoard[x][y] == common.Empty {
-				// Simulate the move
-				currentBoard[x][y] = common.P2
+// BotConfig describes a bot of a built-in engine.
+type BotConfig struct {
+	Engine     string
+	Difficulty string
+	TimeBudget time.Duration // Thinking time of the search engines, DefaultTimeBudget when 0
+	Rand       *rand.Rand    // Source of the random choices, randomly seeded when nil
+	Policy     *Policy       // Policy of the learned engine
+}
 
-				// Evaluate the move
-				score := minimax(currentBoard, 0, false)
+// NewBot creates the bot of an engine and a difficulty level.
+// Easy bots play random moves whatever the engine.
+func NewBot(engine, difficulty string) (Bot, error) {
+	return NewBotFromConfig(BotConfig{Engine: engine, Difficulty: difficulty})
+}
 
-				// Revert the move
-				currentBoard[x][y] = common.Empty
+// NewBotFromConfig creates the bot described by config.
+func NewBotFromConfig(config BotConfig) (Bot, error) {
+	if config.TimeBudget == 0 {
+		config.TimeBudget = DefaultTimeBudget
+	}
+	if config.Rand == nil {
+		config.Rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
 
-				// Update the best move if this move is better
-				if float64(score) > bestScore {
-					bestScore = float64(score)
-					moveX, moveY = x, y
-				}
-			}
-		}
+	switch config.Engine {
+	case common.EngineMinimax:
+		return newMinimaxBot(config)
+	case common.EngineMCTS:
+		return newMCTSBot(config)
+	case common.EngineLearned:
+		return newLearnedBot(config)
 	}
+	return nil, fmt.Errorf("%w: '%s'", ErrUnknownEngine, config.Engine)
+}
 
-	// Return the best move
-	return moveX, moveY
+// newMinimaxBot creates an alpha-beta bot of a difficulty level.
+func newMinimaxBot(config BotConfig) (Bot, error) {
+	rng := config.Rand
+	options := SearchOptions{
+		TimeBudget:          config.TimeBudget,
+		ConquestProbability: BotAccuracy[config.Difficulty],
+	}
+
+	switch config.Difficulty {
+	case common.DifficultyEasy:
+		return NewRandomBot(rng), nil
+	case common.DifficultyMedium:
+		options.MaxDepth = MediumSearchDepth
+		return NewBlunderBot(NewSearchBot(options), MediumBlunderRate, rng), nil
+	case common.DifficultyHard:
+		return NewBlunderBot(NewSearchBot(options), HardBlunderRate, rng), nil
+	case common.DifficultyPerfect:
+		return NewSearchBot(options), nil
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrUnknownDifficulty, config.Difficulty)
 }
 
-// Minimax algorithm to evaluate the board
-func minimax(board [common.BoardSize][common.BoardSize]common.PlayerID, depth int, isMaximizing bool) int {
-	simulatedGame := &GameLogic{Board: board}
+// newMCTSBot creates a Monte Carlo bot of a difficulty level.
+func newMCTSBot(config BotConfig) (Bot, error) {
+	options := MCTSOptions{ConquestProbability: BotAccuracy[config.Difficulty]}
 
-	// Check for win
-	if simulatedGame.checkWin(common.P2) {
-		return MaxDepth - depth
+	switch config.Difficulty {
+	case common.DifficultyEasy:
+		return NewRandomBot(config.Rand), nil
+	case common.DifficultyMedium:
+		options.Iterations = MediumMCTSIterations
+	case common.DifficultyHard:
+		options.Iterations = HardMCTSIterations
+	case common.DifficultyPerfect:
+		options.TimeBudget = config.TimeBudget
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownDifficulty, config.Difficulty)
 	}
-	// Check for loss
-	if simulatedGame.checkWin(common.P1) {
-		return depth - MaxDepth
+	return NewMCTS(options, config.Rand), nil
+}
+
+// newLearnedBot creates a bot sampling its policy with the temperature of a difficulty level.
+// Positions missing from the policy are played by the minimax bot of the same difficulty.
+func newLearnedBot(config BotConfig) (Bot, error) {
+	temperature, ok := LearnedTemperature[config.Difficulty]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownDifficulty, config.Difficulty)
 	}
-	// Check for draw
-	if isBoardFull(board) {
-		return 0
+	if config.Policy == nil {
+		return nil, ErrNoPolicy
 	}
 
-	if isMaximizing {
-		// Maximize score for bot
-		maxEval := math.Inf(-1)
-		for x := range common.BoardSize {
-			for y := range common.BoardSize {
-				if board[x][y] == common.Empty {
-					board[x][y] = common.P2
-					eval := float64(minimax(board, depth+1, false))
-					board[x][y] = common.Empty
-					maxEval = math.Max(maxEval, eval)
-				}
-			}
-		}
-		return int(maxEval)
-	} else {
-		// Minimize score for opponent
-		minEval := math.Inf(1)
-		for x := range common.BoardSize {
-			for y := range common.BoardSize {
-				if board[x][y] == common.Empty {
-					board[x][y] = common.P1
-					eval := float64(minimax(board, depth+1, true))
-					board[x][y] = common.Empty
-					minEval = math.Min(minEval, eval)
-				}
+	fallback, err := newMinimaxBot(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewLearnedBot(config.Policy, temperature, fallback, config.Rand), nil
+}
+
+// BotAnswers simulates a bot of the given difficulty answering a challenge, it returns whether the answer is correct.
+func BotAnswers(difficulty string) bool {
+	return rand.Float64() < BotAccuracy[difficulty]
+}
+
+// RandomBot plays a random legal move.
+type RandomBot struct {
+	rng *rand.Rand
+}
+
+// NewRandomBot creates a random bot drawing from rng.
+func NewRandomBot(rng *rand.Rand) *RandomBot {
+	return &RandomBot{rng: rng}
+}
+
+// NextMove returns a random legal move.
+func (b *RandomBot) NextMove(ctx context.Context, game Rules) Move {
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		return Move{X: InvalidCoord, Y: InvalidCoord}
+	}
+	return moves[b.rng.IntN(len(moves))]
+}
+
+// SearchBot plays the best move found by a Searcher.
+// It keeps the transposition table of its searcher for the whole game.
+type SearchBot struct {
+	searcher *Searcher
+}
+
+// NewSearchBot creates a bot searching with the given options.
+func NewSearchBot(options SearchOptions) *SearchBot {
+	return &SearchBot{searcher: NewSearcher(options)}
+}
+
+// NextMove returns the best move found by the search.
+func (b *SearchBot) NextMove(ctx context.Context, game Rules) Move {
+	return b.searcher.Search(ctx, game).Move
+}
+
+// BlunderBot plays the move of another bot, or a random move with probability Rate.
+type BlunderBot struct {
+	Inner  Bot
+	Rate   float64
+	random *RandomBot
+}
+
+// NewBlunderBot wraps a bot so that it blunders with probability rate.
+func NewBlunderBot(inner Bot, rate float64, rng *rand.Rand) *BlunderBot {
+	return &BlunderBot{
+		Inner:  inner,
+		Rate:   rate,
+		random: NewRandomBot(rng),
+	}
+}
+
+// NextMove returns the move of the inner bot, unless the bot blunders.
+func (b *BlunderBot) NextMove(ctx context.Context, game Rules) Move {
+	if b.random.rng.Float64() < b.Rate {
+		return b.random.NextMove(ctx, game)
+	}
+	return b.Inner.NextMove(ctx, game)
+}
+
+// GetBotMove returns the move of the bot for the player to move, after a thinking delay.
+// It returns InvalidCoord if ctx is done before the move is found.
+func GetBotMove(ctx context.Context, bot Bot, rules Rules, delay time.Duration) (int, int) {
+	// Simulate "thinking" time for natural gameplay flow
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return InvalidCoord, InvalidCoord
+	}
+
+	move := bot.NextMove(ctx, rules)
+	if ctx.Err() != nil {
+		return InvalidCoord, InvalidCoord
+	}
+	return move.X, move.Y
+}
+
+// countEmptyCells counts the cells of the board that are not occupied
+func countEmptyCells(board [][]common.PlayerID) int {
+	count := 0
+	for x := range board {
+		for y := range board[x] {
+			if board[x][y] == common.Empty {
+				count++
 			}
 		}
-		return int(minEval)
 	}
+	return count
 }
 
 // isBoardFull is a helper to check if the board is full
-func isBoardFull(board [common.BoardSize][common.BoardSize]common.PlayerID) bool {
-	for x := range common.BoardSize {
-		for y := range common.BoardSize {
+func isBoardFull(board [][]common.PlayerID) bool {
+	for x := range board {
+		for y := range board[x] {
 			if board[x][y] == common.Empty {
 				return false
 			}
@@ -2,7 +2,11 @@ package logic
 
 import (
 	"Goonker/common"
+	"context"
+	"errors"
+	"math/rand/v2"
 	"testing"
+	"time"
 )
 
 func TestGetBotMove(t *testing.T) {
@@ -14,13 +18,13 @@ func TestGetBotMove(t *testing.T) {
 	   Turn: O (Bot)
 	   Expected: 0,2 (block X)
 	*/
-	logic := NewGameLogic()
+	logic := NewGameLogic(ClassicRules)
 	logic.Board[0][0] = common.P1
 	logic.Board[0][1] = common.P1
 	logic.Board[1][1] = common.P2
 	logic.Turn = common.P2
 
-	x, y := GetBotMove(logic)
+	x, y := GetBotMove(context.Background(), NewSearchBot(DefaultSearchOptions), logic, 0)
 	if x != 0 || y != 2 {
 		t.Errorf("Expected defensive move at 0,2, got %d,%d", x, y)
 	}
@@ -33,21 +37,36 @@ func TestGetBotMove(t *testing.T) {
 	   Turn: O (Bot)
 	   Expected: 0,2 (win)
 	*/
-	logic = NewGameLogic()
+	logic = NewGameLogic(ClassicRules)
 	logic.Board[0][0] = common.P2
 	logic.Board[0][1] = common.P2
 	logic.Board[1][1] = common.P1
 	logic.Board[2][0] = common.P1
 	logic.Turn = common.P2
 
-	x, y = GetBotMove(logic)
+	x, y = GetBotMove(context.Background(), NewSearchBot(DefaultSearchOptions), logic, 0)
 	if x != 0 || y != 2 {
 		t.Errorf("Expected winning move at 0,2, got %d,%d", x, y)
 	}
 }
 
+func TestGetBotMoveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The thinking delay is cut short and no move is played
+	start := time.Now()
+	x, y := GetBotMove(ctx, NewSearchBot(DefaultSearchOptions), NewGameLogic(ClassicRules), time.Minute)
+	if x != InvalidCoord || y != InvalidCoord {
+		t.Errorf("Expected no move, got %d,%d", x, y)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a cancelled bot to stop, took %v", elapsed)
+	}
+}
+
 func TestIsBoardFull(t *testing.T) {
-	board := [common.BoardSize][common.BoardSize]common.PlayerID{}
+	board := newBoard(common.BoardSize, common.BoardSize)
 	if isBoardFull(board) {
 		t.Error("Expected empty board to not be full")
 	}
@@ -62,3 +81,105 @@ func TestIsBoardFull(t *testing.T) {
 		t.Error("Expected filled board to be full")
 	}
 }
+
+func TestGetBotMoveUltimate(t *testing.T) {
+	// O wins the center sub-board and the game with the bottom-right sub-board row
+	game := NewUltimateLogic()
+	game.SubBoards[0][2] = common.P2
+	game.SubBoards[1][2] = common.P2
+	game.Board[6][6] = common.P2
+	game.Board[7][6] = common.P2
+	game.subCounts[2][2] = 2
+	game.ActiveX, game.ActiveY = 2, 2
+	game.Turn = common.P2
+
+	x, y := GetBotMove(context.Background(), NewSearchBot(DefaultSearchOptions), game, 0)
+	if x != 8 || y != 6 {
+		t.Errorf("Expected winning move at 8,6, got %d,%d", x, y)
+	}
+}
+
+func TestNewBot(t *testing.T) {
+	for _, engine := range common.Engines {
+		for _, difficulty := range common.Difficulties {
+			bot, err := NewBot(engine, difficulty)
+			if err != nil {
+				t.Fatalf("Expected %s difficulty %s to be known: %v", engine, difficulty, err)
+			}
+
+			// Every bot must play a legal move
+			game := NewGameLogic(ClassicRules)
+			move := bot.NextMove(context.Background(), game)
+			if err := game.ApplyMove(common.P1, move.X, move.Y); err != nil {
+				t.Errorf("%s difficulty %s played an illegal move %v: %v", engine, difficulty, move, err)
+			}
+		}
+
+		if _, err := NewBot(engine, "impossible"); !errors.Is(err, ErrUnknownDifficulty) {
+			t.Errorf("Expected ErrUnknownDifficulty, got %v", err)
+		}
+	}
+
+	if _, err := NewBot(common.EngineLearned, common.DifficultyPerfect); !errors.Is(err, ErrNoPolicy) {
+		t.Errorf("Expected ErrNoPolicy, got %v", err)
+	}
+	if _, err := NewBot("impossible", common.DifficultyPerfect); !errors.Is(err, ErrUnknownEngine) {
+		t.Errorf("Expected ErrUnknownEngine, got %v", err)
+	}
+}
+
+func TestRandomBotNoMove(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	game.GameOver = true
+
+	move := NewRandomBot(rand.New(rand.NewPCG(1, 2))).NextMove(context.Background(), game)
+	if move.X != InvalidCoord || move.Y != InvalidCoord {
+		t.Errorf("Expected no move, got %v", move)
+	}
+}
+
+func TestBlunderBotRate(t *testing.T) {
+	// X wins at 2,0, any other move is a blunder
+	game := NewGameLogic(ClassicRules)
+	game.Board[0][0] = common.P1
+	game.Board[1][0] = common.P1
+	game.Board[0][1] = common.P2
+	game.Board[1][1] = common.P2
+	game.SymbolCount = 4
+	winning := Move{X: 2, Y: 0}
+
+	never := NewBlunderBot(NewSearchBot(SearchOptions{}), 0, rand.New(rand.NewPCG(1, 2)))
+	always := NewBlunderBot(NewSearchBot(SearchOptions{}), 1, rand.New(rand.NewPCG(1, 2)))
+
+	blunders := 0
+	for range 50 {
+		if move := never.NextMove(context.Background(), game); move != winning {
+			t.Fatalf("Expected the bot to never blunder, got %v", move)
+		}
+		if always.NextMove(context.Background(), game) != winning {
+			blunders++
+		}
+	}
+
+	// 1 of the 5 random moves wins
+	if blunders == 0 {
+		t.Error("Expected the bot to blunder")
+	}
+}
+
+func TestBotAnswers(t *testing.T) {
+	const draws = 2000
+	for _, difficulty := range common.Difficulties {
+		correct := 0
+		for range draws {
+			if BotAnswers(difficulty) {
+				correct++
+			}
+		}
+
+		rate, expected := float64(correct)/draws, BotAccuracy[difficulty]
+		if rate < expected-0.05 || rate > expected+0.05 {
+			t.Errorf("Expected %s bot accuracy near %.2f, got %.2f", difficulty, expected, rate)
+		}
+	}
+}
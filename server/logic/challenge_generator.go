@@ -0,0 +1,306 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"Goonker/common"
+)
+
+// Challenge generator constants
+const (
+	// Tag of every generated challenge
+	GeneratedChallengeTag = "generated"
+
+	// Answers of a generated challenge, like the ones of the embedded challenges
+	generatedAnswers = 3
+
+	// Attempts to generate an answer not asked yet before giving up on freshness
+	maxGenerateAttempts = 10
+)
+
+// challengeKind generates one kind of challenge, in a single category.
+type challengeKind struct {
+	name     string
+	category string
+	generate func(rng *rand.Rand, difficulty int) Challenge
+}
+
+// challengeKinds lists the generated challenges.
+var challengeKinds = []challengeKind{
+	{"arithmetic", common.CategoryMath, generateArithmetic},
+	{"sequence", common.CategoryMath, generateSequence},
+	{"conversion", common.CategoryScience, generateConversion},
+	{"anagram", common.CategoryGeography, generateAnagram},
+}
+
+// ChallengeGenerator is a challenge source creating new challenges on demand.
+type ChallengeGenerator struct {
+	rng           *rand.Rand
+	kinds         []challengeKind // Kinds allowed by the filter
+	minDifficulty int
+	maxDifficulty int
+	asked         map[string]bool // Kinds and correct answers already generated, so that a word is not scrambled twice
+}
+
+// NewChallengeGenerator creates a generator of every kind and difficulty of challenges.
+func NewChallengeGenerator(rng *rand.Rand) *ChallengeGenerator {
+	generator := &ChallengeGenerator{rng: rng, asked: make(map[string]bool)}
+	_ = generator.SetFilter(common.ChallengeFilter{})
+	return generator
+}
+
+// SetFilter restricts the challenges generated to the ones allowed by filter.
+func (g *ChallengeGenerator) SetFilter(filter common.ChallengeFilter) error {
+	if err := ValidateChallengeFilter(filter); err != nil {
+		return err
+	}
+
+	var kinds []challengeKind
+	for _, kind := range challengeKinds {
+		if len(filter.Categories) == 0 || slices.Contains(filter.Categories, kind.category) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	minDifficulty := max(filter.MinDifficulty, common.ChallengeEasy)
+	maxDifficulty := common.ChallengeHard
+	if filter.MaxDifficulty > 0 {
+		maxDifficulty = min(filter.MaxDifficulty, common.ChallengeHard)
+	}
+	if len(kinds) == 0 || minDifficulty > maxDifficulty {
+		return ErrNoChallenges
+	}
+
+	g.kinds = kinds
+	g.minDifficulty, g.maxDifficulty = minDifficulty, maxDifficulty
+	return nil
+}
+
+// PickChallenge generates a challenge allowed by the filter, avoiding the answers already asked.
+func (g *ChallengeGenerator) PickChallenge() (*Challenge, error) {
+	if len(g.kinds) == 0 {
+		return nil, ErrNoChallenges
+	}
+
+	var challenge Challenge
+	var key string
+	for range maxGenerateAttempts {
+		kind := g.kinds[g.rng.IntN(len(g.kinds))]
+		difficulty := g.minDifficulty + g.rng.IntN(g.maxDifficulty-g.minDifficulty+1)
+
+		challenge = kind.generate(g.rng, difficulty)
+		challenge.Category = kind.category
+		challenge.Difficulty = difficulty
+		challenge.Tags = []string{GeneratedChallengeTag, kind.name}
+		challenge.setDefaults()
+		key = kind.name + ":" + challenge.Answers[challenge.AnswerKey]
+		if !g.asked[key] {
+			break
+		}
+	}
+
+	g.asked[key] = true
+	return &challenge, nil
+}
+
+// generateArithmetic asks the result of an operation, the distractors being close results or common mistakes.
+func generateArithmetic(rng *rand.Rand, difficulty int) Challenge {
+	var question string
+	var answer int
+	var mistakes []int
+	switch difficulty {
+	case common.ChallengeEasy:
+		a, b := 2+rng.IntN(19), 2+rng.IntN(19)
+		if rng.IntN(2) == 0 {
+			question, answer = fmt.Sprintf("What is %d + %d?", a, b), a+b
+			mistakes = []int{a + b + 1, a + b - 1, a + b + 10}
+		} else {
+			a, b = max(a, b), min(a, b)
+			question, answer = fmt.Sprintf("What is %d - %d?", a+b, b), a
+			mistakes = []int{a + 1, a - 1, a + 2*b}
+		}
+	case common.ChallengeMedium:
+		a, b := 3+rng.IntN(10), 3+rng.IntN(10)
+		question, answer = fmt.Sprintf("What is %d x %d?", a, b), a*b
+		mistakes = []int{a * (b + 1), (a - 1) * b, a*b + 10, a + b}
+	default:
+		a, b, c := 11+rng.IntN(15), 3+rng.IntN(13), 2+rng.IntN(40)
+		question, answer = fmt.Sprintf("What is %d x %d + %d?", a, b, c), a*b+c
+		mistakes = []int{a * (b + c), a*b + c + 10, a*(b+1) + c, a*b - c}
+	}
+	return numberChallenge(rng, question, answer, mistakes)
+}
+
+// generateSequence asks the next term of a sequence, the distractors applying a wrong rule.
+func generateSequence(rng *rand.Rand, difficulty int) Challenge {
+	var terms []int
+	switch difficulty {
+	case common.ChallengeEasy:
+		// Constant step
+		term, step := 1+rng.IntN(20), 2+rng.IntN(8)
+		for range 5 {
+			terms = append(terms, term)
+			term += step
+		}
+	case common.ChallengeMedium:
+		// Constant ratio
+		term, ratio := 1+rng.IntN(5), 2+rng.IntN(2)
+		for range 5 {
+			terms = append(terms, term)
+			term *= ratio
+		}
+	default:
+		// Growing step, or the sum of the two previous terms
+		if rng.IntN(2) == 0 {
+			term, step, growth := 1+rng.IntN(10), 1+rng.IntN(5), 2+rng.IntN(3)
+			for range 6 {
+				terms = append(terms, term)
+				term += step
+				step += growth
+			}
+		} else {
+			terms = []int{1 + rng.IntN(5), 2 + rng.IntN(5)}
+			for len(terms) < 6 {
+				terms = append(terms, terms[len(terms)-1]+terms[len(terms)-2])
+			}
+		}
+	}
+
+	shown, answer := terms[:len(terms)-1], terms[len(terms)-1]
+	last, lastStep := shown[len(shown)-1], shown[len(shown)-1]-shown[len(shown)-2]
+	question := fmt.Sprintf("What comes next: %s, ?", joinInts(shown))
+	return numberChallenge(rng, question, answer, []int{last + lastStep, answer + lastStep, answer + 1, answer - 1})
+}
+
+// unitConversion converts a quantity of a unit into another one.
+type unitConversion struct {
+	from, to string
+	factor   float64
+}
+
+// unitConversions lists the conversions of each difficulty, the hard ones needing rounding.
+var unitConversions = map[int][]unitConversion{
+	common.ChallengeEasy: {
+		{"kilometers", "meters", 1000},
+		{"meters", "centimeters", 100},
+		{"centimeters", "millimeters", 10},
+		{"kilograms", "grams", 1000},
+		{"liters", "milliliters", 1000},
+	},
+	common.ChallengeMedium: {
+		{"hours", "minutes", 60},
+		{"minutes", "seconds", 60},
+		{"days", "hours", 24},
+		{"weeks", "days", 7},
+		{"hours", "seconds", 3600},
+	},
+	common.ChallengeHard: {
+		{"inches", "centimeters", 2.54},
+		{"miles", "kilometers", 1.609},
+		{"feet", "centimeters", 30.48},
+		{"pounds", "kilograms", 0.4536},
+		{"gallons", "liters", 3.785},
+	},
+}
+
+// generateConversion asks a unit conversion, the distractors using a wrong factor.
+func generateConversion(rng *rand.Rand, difficulty int) Challenge {
+	conversions := unitConversions[difficulty]
+	conversion := conversions[rng.IntN(len(conversions))]
+
+	if difficulty != common.ChallengeHard {
+		value := 2 + rng.IntN(11)
+		answer := value * int(conversion.factor)
+		question := fmt.Sprintf("How many %s are %d %s?", conversion.to, value, conversion.from)
+		return numberChallenge(rng, question, answer, []int{answer / 10, answer * 10, value * 100, answer + int(conversion.factor)})
+	}
+
+	// Rounded answers, far enough apart to tell them
+	value := 10 + rng.IntN(41)
+	answer := int(math.Round(float64(value) * conversion.factor))
+	inverse := int(math.Round(float64(value) / conversion.factor))
+	question := fmt.Sprintf("About how many %s are %d %s?", conversion.to, value, conversion.from)
+	return numberChallenge(rng, question, answer, []int{inverse, answer * 2, answer / 2, answer + value})
+}
+
+// anagramWords lists the capitals to unscramble, by difficulty.
+var anagramWords = map[int][]string{
+	common.ChallengeEasy:   {"Bern", "Rome", "Oslo", "Lima", "Paris", "Cairo", "Delhi", "Tokyo", "Seoul", "Quito"},
+	common.ChallengeMedium: {"Berlin", "Madrid", "Lisbon", "London", "Vienna", "Prague", "Dublin", "Ottawa", "Nairobi", "Bangkok"},
+	common.ChallengeHard:   {"Budapest", "Helsinki", "Canberra", "Stockholm", "Amsterdam", "Copenhagen", "Wellington", "Bucharest", "Reykjavik", "Montevideo"},
+}
+
+// generateAnagram asks to unscramble a capital, the distractors being other capitals of close length.
+func generateAnagram(rng *rand.Rand, difficulty int) Challenge {
+	words := anagramWords[difficulty]
+	word := words[rng.IntN(len(words))]
+
+	letters := []rune(strings.ToUpper(word))
+	scrambled := string(letters)
+	for scrambled == strings.ToUpper(word) {
+		rng.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
+		scrambled = string(letters)
+	}
+
+	// The closest lengths make the answer harder to guess
+	others := slices.DeleteFunc(slices.Clone(words), func(other string) bool { return other == word })
+	rng.Shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	slices.SortStableFunc(others, func(a, b string) int {
+		return abs(len(a)-len(word)) - abs(len(b)-len(word))
+	})
+
+	return Challenge{
+		Type:     common.ChallengeChoice,
+		Question: fmt.Sprintf("Unscramble this capital city: %s", scrambled),
+		Answers:  append([]string{word}, others[:generatedAnswers-1]...),
+	}
+}
+
+// numberChallenge creates a choice challenge whose first answer is correct,
+// the distractors being picked among the mistakes, or close numbers if they are not enough.
+func numberChallenge(rng *rand.Rand, question string, answer int, mistakes []int) Challenge {
+	rng.Shuffle(len(mistakes), func(i, j int) { mistakes[i], mistakes[j] = mistakes[j], mistakes[i] })
+
+	answers := []int{answer}
+	for _, mistake := range mistakes {
+		if len(answers) == generatedAnswers {
+			break
+		}
+		if mistake > 0 && !slices.Contains(answers, mistake) {
+			answers = append(answers, mistake)
+		}
+	}
+	for offset := 1; len(answers) < generatedAnswers; offset++ {
+		if !slices.Contains(answers, answer+offset) {
+			answers = append(answers, answer+offset)
+		}
+	}
+
+	challenge := Challenge{Type: common.ChallengeChoice, Question: question}
+	for _, n := range answers {
+		challenge.Answers = append(challenge.Answers, strconv.Itoa(n))
+	}
+	return challenge
+}
+
+// joinInts formats numbers as a comma separated list.
+func joinInts(numbers []int) string {
+	formatted := make([]string, len(numbers))
+	for i, n := range numbers {
+		formatted[i] = strconv.Itoa(n)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
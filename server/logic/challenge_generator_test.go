@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"Goonker/common"
+)
+
+func TestChallengeGenerator(t *testing.T) {
+	generator := NewChallengeGenerator(rand.New(rand.NewPCG(1, 2)))
+
+	for _, kind := range challengeKinds {
+		for difficulty := common.ChallengeEasy; difficulty <= common.ChallengeHard; difficulty++ {
+			for range 50 {
+				challenge := kind.generate(generator.rng, difficulty)
+				challenge.Category, challenge.Difficulty = kind.category, difficulty
+				challenge.setDefaults()
+				if err := challenge.Validate(); err != nil {
+					t.Fatalf("%s %d: invalid challenge %+v: %v", kind.name, difficulty, challenge, err)
+				}
+				if len(challenge.Answers) != generatedAnswers {
+					t.Fatalf("%s %d: expected %d answers, got %v", kind.name, difficulty, generatedAnswers, challenge.Answers)
+				}
+				for i, answer := range challenge.Answers {
+					if slices.Index(challenge.Answers, answer) != i {
+						t.Fatalf("%s %d: repeated answer in %v", kind.name, difficulty, challenge.Answers)
+					}
+				}
+			}
+		}
+	}
+
+	// Only easy math challenges
+	filter := common.ChallengeFilter{Categories: []string{common.CategoryMath}, MaxDifficulty: common.ChallengeEasy}
+	if err := generator.SetFilter(filter); err != nil {
+		t.Fatalf("Expected a valid filter, got %v", err)
+	}
+	for range 20 {
+		challenge, err := generator.PickChallenge()
+		if err != nil || !challenge.Matches(filter) || !slices.Contains(challenge.Tags, GeneratedChallengeTag) {
+			t.Fatalf("Expected a generated challenge allowed by the filter, got %+v (%v)", challenge, err)
+		}
+	}
+
+	if err := generator.SetFilter(common.ChallengeFilter{Categories: []string{common.CategoryHistory}}); !errors.Is(err, ErrNoChallenges) {
+		t.Errorf("Expected ErrNoChallenges for history, got %v", err)
+	}
+}
+
+func TestMixedChallengeSource(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	pool := &ChallengeManager{challenges: []Challenge{
+		{Question: "History", Answers: []string{"A", "B"}, Category: common.CategoryHistory, Difficulty: common.ChallengeEasy},
+	}}
+	source := NewMixedChallengeSource(rng, []ChallengeSource{pool, NewChallengeGenerator(rng)}, []float64{0.5, 0.5})
+	if err := source.SetFilter(common.ChallengeFilter{}); err != nil {
+		t.Fatalf("Expected every challenge to be allowed, got %v", err)
+	}
+
+	// Both sources are used
+	generated := 0
+	for range 100 {
+		challenge, err := source.PickChallenge()
+		if err != nil {
+			t.Fatalf("Expected a challenge, got %v", err)
+		}
+		if slices.Contains(challenge.Tags, GeneratedChallengeTag) {
+			generated++
+		}
+	}
+	if generated == 0 || generated == 100 {
+		t.Errorf("Expected challenges from both sources, got %d generated out of 100", generated)
+	}
+
+	// The generator has no history challenge, the pool no math one
+	for _, category := range []string{common.CategoryHistory, common.CategoryMath} {
+		if err := source.SetFilter(common.ChallengeFilter{Categories: []string{category}}); err != nil {
+			t.Fatalf("Expected %s challenges, got %v", category, err)
+		}
+		for range 10 {
+			if challenge, err := source.PickChallenge(); err != nil || challenge.Category != category {
+				t.Fatalf("Expected a %s challenge, got %+v (%v)", category, challenge, err)
+			}
+		}
+	}
+
+	if err := source.SetFilter(common.ChallengeFilter{Categories: []string{common.CategoryCulture}}); !errors.Is(err, ErrNoChallenges) {
+		t.Errorf("Expected ErrNoChallenges, got %v", err)
+	}
+	if err := source.SetFilter(common.ChallengeFilter{Categories: []string{"cooking"}}); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("Expected ErrUnknownCategory, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"Goonker/common"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TrueFalseAnswers are the answers of the true/false challenges, in this order.
+var TrueFalseAnswers = []string{"True", "False"}
+
+// Payload returns the challenge as sent to the players, without its solution.
+func (c *Challenge) Payload() common.ChallengePayload {
+	payload := common.ChallengePayload{
+		Type:       c.Type,
+		Question:   c.Question,
+		Category:   c.Category,
+		Difficulty: c.Difficulty,
+		Minigame:   c.Minigame,
+	}
+	if c.HasChoices() || c.Type == common.ChallengeOrder {
+		payload.Answers = c.Answers
+	}
+	return payload
+}
+
+// HasChoices checks if the challenge is answered by picking one of its answers.
+func (c *Challenge) HasChoices() bool {
+	return c.Type == "" || c.Type == common.ChallengeChoice || c.Type == common.ChallengeTrueFalse
+}
+
+// Grade checks if an answer to the challenge, as sent by its payload, is correct.
+// Elapsed is the time the player took to answer, measured by the server without the network round trip, used by minigames.
+func (c *Challenge) Grade(answer common.AnswerPayload, elapsed time.Duration) bool {
+	switch c.Type {
+	case common.ChallengeMinigame:
+		return c.Minigame != nil && GradeMinigame(*c.Minigame, answer.Events, elapsed)
+	case common.ChallengeNumeric:
+		return answer.Number != nil && math.Abs(*answer.Number-c.Number) <= c.Tolerance
+	case common.ChallengeText:
+		given := NormalizeAnswer(answer.Text)
+		return given != "" && slices.ContainsFunc(c.Answers, func(accepted string) bool {
+			return NormalizeAnswer(accepted) == given
+		})
+	case common.ChallengeOrder:
+		solution := c.solution
+		if solution == nil {
+			solution = c.Answers
+		}
+		if len(answer.Order) != len(solution) {
+			return false
+		}
+
+		// Items are unique, so matching every position also rules out repeated indices
+		for i, index := range answer.Order {
+			if index < 0 || index >= len(c.Answers) || c.Answers[index] != solution[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return answer.Answer == c.AnswerKey
+}
+
+// Solution returns the right answer of the challenge as shown to the players, empty for minigames.
+func (c *Challenge) Solution() string {
+	switch c.Type {
+	case common.ChallengeMinigame:
+		return ""
+	case common.ChallengeNumeric:
+		return strconv.FormatFloat(c.Number, 'f', -1, 64)
+	case common.ChallengeText:
+		if len(c.Answers) == 0 {
+			return ""
+		}
+		return c.Answers[0]
+	case common.ChallengeOrder:
+		solution := c.solution
+		if solution == nil {
+			solution = c.Answers
+		}
+		return strings.Join(solution, ", ")
+	}
+	if c.AnswerKey < 0 || c.AnswerKey >= len(c.Answers) {
+		return ""
+	}
+	return c.Answers[c.AnswerKey]
+}
+
+// accentRemover strips the accents of a decomposed text.
+var accentRemover = runes.Remove(runes.In(unicode.Mn))
+
+// NormalizeAnswer lowers a typed answer and keeps its words only, without accents,
+// so that "Zürich" matches "zurich" and "Saint-Gall" matches "saint gall".
+func NormalizeAnswer(answer string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, accentRemover, norm.NFC), answer)
+	if err != nil {
+		stripped = answer
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(stripped), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -1,59 +1,128 @@
 package logic
 
 import (
-	"Goonker/server/assets"
-	"encoding/json"
+	"Goonker/common"
+	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 
 	"github.com/bits-and-blooms/bitset"
 )
 
+// Challenge defaults
+const (
+	// Challenges without a language are in English
+	DefaultChallengeLanguage = "en"
+
+	// Challenges without a difficulty are of medium difficulty
+	DefaultChallengeDifficulty = common.ChallengeMedium
+)
+
+// Error messages
+var (
+	ErrNoChallenges      = errors.New("no challenges available")
+	ErrUnknownCategory   = errors.New("unknown challenge category")
+	ErrInvalidDifficulty = errors.New("invalid challenge difficulty range")
+)
+
 // ChallengeManager handles the challenges
 type ChallengeManager struct {
 	challenges      []Challenge
+	pool            []int // Indices of the challenges that may be picked
 	askedChallenges bitset.BitSet
 }
 
 // Challenge represents a challenge
 type Challenge struct {
-	Question  string   `json:"question"`
-	Answers   []string `json:"answers"`
-	AnswerKey int      `json:"answer_key"`
+	Type       string   `json:"type" yaml:"type"` // One of common.ChallengeTypes
+	Question   string   `json:"question" yaml:"question"`
+	Answers    []string `json:"answers" yaml:"answers"` // Choices, accepted answers of text challenges, or items in the expected order
+	AnswerKey  int      `json:"answer_key" yaml:"answer_key"`
+	Category   string   `json:"category" yaml:"category"`
+	Difficulty int      `json:"difficulty" yaml:"difficulty"` // From common.ChallengeEasy to common.ChallengeHard
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Language   string   `json:"language" yaml:"language"`
+
+	// True/false challenges
+	Correct bool `json:"correct,omitempty" yaml:"correct,omitempty"` // Whether the statement is true
+
+	// Numeric challenges
+	Number    float64 `json:"number,omitempty" yaml:"number,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty" yaml:"tolerance,omitempty"` // Largest accepted difference with Number
+
+	// Minigames, drawn by MinigameGenerator and never part of challenge packs
+	Minigame *common.MinigamePayload `json:"-" yaml:"-"`
+
+	solution []string // Items of an ordering challenge in the expected order, once shuffled
 }
 
-// NewChallengeManager creates a new challenge manager
+// NewChallengeManager creates a challenge manager over the current challenge pool.
+// The manager keeps this pool even if it is swapped later.
 func NewChallengeManager() (*ChallengeManager, error) {
-	// Load challenges from json file
-	challengesByte, err := assets.AssetsFS.ReadFile("challenges.json")
+	challenges, err := Challenges()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read challenges file: %w", err)
+		return nil, err
 	}
 
 	// Initialize challenge manager
-	challengeManager := &ChallengeManager{}
+	challengeManager := &ChallengeManager{challenges: challenges}
 
-	if err := json.Unmarshal(challengesByte, &challengeManager.challenges); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal challenges: %w", err)
+	// Every challenge may be picked until a filter is set
+	if err := challengeManager.SetFilter(common.ChallengeFilter{}); err != nil {
+		return nil, err
 	}
 
-	// Initialize asked challenges
-	challengeManager.askedChallenges = *bitset.New(uint(len(challengeManager.challenges)))
-
 	return challengeManager, nil
 }
 
+// SetFilter restricts the challenges picked to the ones allowed by filter.
+func (m *ChallengeManager) SetFilter(filter common.ChallengeFilter) error {
+	if err := ValidateChallengeFilter(filter); err != nil {
+		return err
+	}
+
+	var pool []int
+	for i := range m.challenges {
+		if m.challenges[i].Matches(filter) {
+			pool = append(pool, i)
+		}
+	}
+	if len(pool) == 0 {
+		return ErrNoChallenges
+	}
+
+	m.pool = pool
+	m.askedChallenges = *bitset.New(uint(len(pool)))
+	return nil
+}
+
+// ValidateChallengeFilter checks that a filter only uses known categories and a valid difficulty range.
+func ValidateChallengeFilter(filter common.ChallengeFilter) error {
+	for _, category := range filter.Categories {
+		if !slices.Contains(common.Categories, category) {
+			return fmt.Errorf("%w: '%s'", ErrUnknownCategory, category)
+		}
+	}
+
+	minDifficulty, maxDifficulty := filter.MinDifficulty, filter.MaxDifficulty
+	if minDifficulty < 0 || maxDifficulty < 0 || (maxDifficulty > 0 && minDifficulty > maxDifficulty) {
+		return fmt.Errorf("%w: %d to %d", ErrInvalidDifficulty, minDifficulty, maxDifficulty)
+	}
+	return nil
+}
+
 // PickChallenge returns a challenge from the challenges list
 func (m *ChallengeManager) PickChallenge() (*Challenge, error) {
-	if m.challenges == nil {
-		return nil, fmt.Errorf("no challenges loaded")
+	if len(m.pool) == 0 {
+		return nil, ErrNoChallenges
 	}
 
 	// To avoid picking the same challenge multiple times in the same game,
 	// we use a bitset to store the challenges that have already been picked
 	randIndex := 0
 	for {
-		randIndex = rand.Intn(len(m.challenges))
+		randIndex = rand.Intn(len(m.pool))
 
 		// In case every challenges have been picked once, the bitset is cleared
 		if m.askedChallenges.All() {
@@ -66,13 +135,65 @@ func (m *ChallengeManager) PickChallenge() (*Challenge, error) {
 		}
 	}
 
-	challenge := &m.challenges[randIndex]
-	return challenge, nil
+	// The pool is shared with other rooms, the challenge is copied so that it can be shuffled
+	challenge := m.challenges[m.pool[randIndex]]
+	challenge.Answers = slices.Clone(challenge.Answers)
+	return &challenge, nil
+}
+
+// Matches checks if the challenge is allowed by a filter.
+func (c *Challenge) Matches(filter common.ChallengeFilter) bool {
+	if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, c.Category) {
+		return false
+	}
+	if filter.MinDifficulty > 0 && c.Difficulty < filter.MinDifficulty {
+		return false
+	}
+	return filter.MaxDifficulty == 0 || c.Difficulty <= filter.MaxDifficulty
+}
+
+// setDefaults fills the optional fields of a challenge.
+func (c *Challenge) setDefaults() {
+	if c.Type == "" {
+		c.Type = common.ChallengeChoice
+	}
+	if c.Type == common.ChallengeTrueFalse {
+		c.Answers = slices.Clone(TrueFalseAnswers)
+		c.AnswerKey = 0
+		if !c.Correct {
+			c.AnswerKey = 1
+		}
+	}
+	if c.Difficulty == 0 {
+		c.Difficulty = DefaultChallengeDifficulty
+	}
+	if c.Language == "" {
+		c.Language = DefaultChallengeLanguage
+	}
 }
 
 // Shuffle the order of the answers
+// True/false answers keep their order, ordering challenges are never left in the expected order.
 func (c *Challenge) Shuffle() {
+	switch c.Type {
+	case common.ChallengeTrueFalse, common.ChallengeNumeric, common.ChallengeText, common.ChallengeMinigame:
+		return
+	case common.ChallengeOrder:
+		if c.solution == nil {
+			c.solution = slices.Clone(c.Answers)
+		}
+		for {
+			c.shuffleAnswers()
+			if !slices.Equal(c.Answers, c.solution) {
+				return
+			}
+		}
+	}
+	c.shuffleAnswers()
+}
 
+// shuffleAnswers shuffles the answers, keeping track of the answer key.
+func (c *Challenge) shuffleAnswers() {
 	for i := range c.Answers {
 		j := rand.Intn(i + 1)
 
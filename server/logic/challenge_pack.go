@@ -0,0 +1,296 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"Goonker/common"
+	"Goonker/server/assets"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Challenge pack constants
+const (
+	// Embedded challenges, always part of the pool
+	DefaultChallengesFile = "challenges.json"
+
+	// Time between two scans of the challenge directory
+	DefaultChallengeReloadInterval = 5 * time.Second
+
+	// Fewest answers of a challenge
+	MinChallengeAnswers = 2
+)
+
+// ChallengePackExts lists the extensions of the challenge packs, other files are ignored.
+var ChallengePackExts = []string{".json", ".yaml", ".yml"}
+
+// Error messages
+var (
+	ErrInvalidChallenge    = errors.New("invalid challenge")
+	ErrUnknownPackFormat   = errors.New("unknown challenge pack format")
+	ErrInvalidChallengeDir = errors.New("invalid challenge directory")
+)
+
+// challengePool holds the challenges of new rooms once packs are loaded, the embedded ones before.
+// Rooms keep the pool they were created with, so swapping it never affects running games.
+var challengePool atomic.Pointer[[]Challenge]
+
+// defaultChallenges parses the embedded challenges once.
+var defaultChallenges = sync.OnceValues(func() ([]Challenge, error) {
+	data, err := assets.AssetsFS.ReadFile(DefaultChallengesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenges file: %w", err)
+	}
+	return ParseChallengePack(DefaultChallengesFile, data)
+})
+
+// Challenges returns the challenge pool of new rooms.
+func Challenges() ([]Challenge, error) {
+	if pool := challengePool.Load(); pool != nil {
+		return *pool, nil
+	}
+	return defaultChallenges()
+}
+
+// SetChallenges replaces the challenge pool of new rooms.
+func SetChallenges(challenges []Challenge) {
+	challengePool.Store(&challenges)
+}
+
+// ParseChallengePack reads a list of challenges in the format given by the extension of name.
+// Missing optional fields are filled and every challenge is validated.
+func ParseChallengePack(name string, data []byte) ([]Challenge, error) {
+	challenges, err := DecodeChallengePack(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range challenges {
+		if err := challenges[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: challenge %d: %w", name, i+1, err)
+		}
+	}
+	return challenges, nil
+}
+
+// DecodeChallengePack reads a list of challenges like ParseChallengePack, without validating them.
+func DecodeChallengePack(name string, data []byte) ([]Challenge, error) {
+	var challenges []Challenge
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(data, &challenges)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &challenges)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownPackFormat, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	for i := range challenges {
+		challenges[i].setDefaults()
+	}
+	return challenges, nil
+}
+
+// EncodeChallengePack writes a list of challenges in the format given by the extension of name.
+func EncodeChallengePack(name string, challenges []Challenge) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return json.MarshalIndent(challenges, "", "    ")
+	case ".yaml", ".yml":
+		return yaml.Marshal(challenges)
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrUnknownPackFormat, name)
+}
+
+// LoadChallengePacks returns the embedded challenges followed by the ones of the packs of dir, in name order.
+// Nothing is returned if a pack is invalid.
+func LoadChallengePacks(dir string) ([]Challenge, error) {
+	embedded, err := defaultChallenges()
+	if err != nil {
+		return nil, err
+	}
+	challenges := slices.Clone(embedded)
+
+	files, err := ChallengePackFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		pack, err := ParseChallengePack(filepath.Base(file), data)
+		if err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, pack...)
+	}
+	return challenges, nil
+}
+
+// ChallengeWatcher keeps the challenge pool in sync with the packs of a directory.
+type ChallengeWatcher struct {
+	dir       string
+	signature string // Packs of the pool in use
+	loaded    bool
+}
+
+// NewChallengeWatcher loads the packs of dir into the challenge pool.
+func NewChallengeWatcher(dir string) (*ChallengeWatcher, error) {
+	watcher := &ChallengeWatcher{dir: dir}
+	if _, err := watcher.Reload(); err != nil {
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// Reload swaps the challenge pool if the packs changed since the last scan, it returns whether it did.
+// The pool is kept when a pack is invalid.
+func (w *ChallengeWatcher) Reload() (bool, error) {
+	signature, err := challengePackSignature(w.dir)
+	if err != nil {
+		return false, err
+	}
+	if w.loaded && signature == w.signature {
+		return false, nil
+	}
+
+	// A failed load is only reported once, until the packs change again
+	w.signature = signature
+	challenges, err := LoadChallengePacks(w.dir)
+	if err != nil {
+		return false, err
+	}
+	w.loaded = true
+	SetChallenges(challenges)
+	return true, nil
+}
+
+// Run reloads the packs every interval until ctx is done.
+func (w *ChallengeWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := w.Reload()
+		if err != nil {
+			log.Printf("Challenge packs not reloaded, keeping the previous ones: %v", err)
+			continue
+		}
+		if changed {
+			challenges, _ := Challenges()
+			log.Printf("Challenge packs reloaded, %d challenges", len(challenges))
+		}
+	}
+}
+
+// ChallengePackFiles returns the paths of the packs of dir, in name order.
+func ChallengePackFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChallengeDir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.Type().IsRegular() && slices.Contains(ChallengePackExts, ext) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// challengePackSignature summarizes the names, sizes and modification times of the packs of dir.
+func challengePackSignature(dir string) (string, error) {
+	files, err := ChallengePackFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var signature strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&signature, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return signature.String(), nil
+}
+
+// Validate checks that a challenge can be asked, graded and filtered.
+func (c *Challenge) Validate() error {
+	switch {
+	case strings.TrimSpace(c.Question) == "":
+		return fmt.Errorf("%w: empty question", ErrInvalidChallenge)
+	case c.Type != "" && !slices.Contains(common.ChallengeTypes, c.Type):
+		return fmt.Errorf("%w: unknown type '%s'", ErrInvalidChallenge, c.Type)
+	case !slices.Contains(common.Categories, c.Category):
+		return fmt.Errorf("%w: unknown category '%s'", ErrInvalidChallenge, c.Category)
+	case c.Difficulty < common.ChallengeEasy || c.Difficulty > common.ChallengeHard:
+		return fmt.Errorf("%w: difficulty %d out of range", ErrInvalidChallenge, c.Difficulty)
+	}
+
+	switch c.Type {
+	case common.ChallengeNumeric:
+		if c.Tolerance < 0 || math.IsNaN(c.Tolerance) {
+			return fmt.Errorf("%w: invalid tolerance %v", ErrInvalidChallenge, c.Tolerance)
+		}
+	case common.ChallengeText:
+		if len(c.Answers) == 0 {
+			return fmt.Errorf("%w: no accepted answer", ErrInvalidChallenge)
+		}
+		for i, answer := range c.Answers {
+			if NormalizeAnswer(answer) == "" {
+				return fmt.Errorf("%w: answer %d has no letter or digit", ErrInvalidChallenge, i+1)
+			}
+		}
+	case common.ChallengeOrder:
+		if len(c.Answers) < MinChallengeAnswers {
+			return fmt.Errorf("%w: %d items, at least %d expected", ErrInvalidChallenge, len(c.Answers), MinChallengeAnswers)
+		}
+		for i, answer := range c.Answers {
+			if slices.Index(c.Answers, answer) != i {
+				return fmt.Errorf("%w: item %d repeated", ErrInvalidChallenge, i+1)
+			}
+		}
+	default:
+		if len(c.Answers) < MinChallengeAnswers {
+			return fmt.Errorf("%w: %d answers, at least %d expected", ErrInvalidChallenge, len(c.Answers), MinChallengeAnswers)
+		}
+		if c.AnswerKey < 0 || c.AnswerKey >= len(c.Answers) {
+			return fmt.Errorf("%w: answer key %d out of range", ErrInvalidChallenge, c.AnswerKey)
+		}
+	}
+
+	for i, answer := range c.Answers {
+		if strings.TrimSpace(answer) == "" {
+			return fmt.Errorf("%w: empty answer %d", ErrInvalidChallenge, i+1)
+		}
+	}
+	return nil
+}
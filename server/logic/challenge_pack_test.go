@@ -0,0 +1,170 @@
+package logic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"Goonker/common"
+)
+
+const testYAMLPack = `
+- question: Which river flows through Fribourg?
+  answers: [Sarine, Rhine, Aare]
+  answer_key: 0
+  category: geography
+  tags: [switzerland]
+`
+
+const testJSONPack = `[{"question": "What is 6 x 7?", "answers": ["42", "48"], "answer_key": 0, "category": "math", "difficulty": 1}]`
+
+func TestParseChallengePack(t *testing.T) {
+	challenges, err := ParseChallengePack("rivers.yaml", []byte(testYAMLPack))
+	if err != nil {
+		t.Fatalf("Expected a valid YAML pack, got %v", err)
+	}
+	if len(challenges) != 1 || challenges[0].Answers[challenges[0].AnswerKey] != "Sarine" {
+		t.Fatalf("Unexpected challenges %+v", challenges)
+	}
+	if challenges[0].Difficulty != DefaultChallengeDifficulty || challenges[0].Language != DefaultChallengeLanguage {
+		t.Errorf("Expected the default difficulty and language, got %+v", challenges[0])
+	}
+
+	if _, err := ParseChallengePack("math.json", []byte(testJSONPack)); err != nil {
+		t.Errorf("Expected a valid JSON pack, got %v", err)
+	}
+
+	// True/false challenges get their answers
+	challenges, err = ParseChallengePack("facts.yaml", []byte("- {type: true_false, question: Q, correct: true, category: science}"))
+	if err != nil || !slices.Equal(challenges[0].Answers, TrueFalseAnswers) || challenges[0].AnswerKey != 0 {
+		t.Errorf("Expected a true/false challenge answered True, got %+v (%v)", challenges, err)
+	}
+
+	errorCases := []struct {
+		name, data string
+		err        error
+	}{
+		{"pack.txt", testJSONPack, ErrUnknownPackFormat},
+		{"pack.json", `[{"question": "Q?", "answers": ["A"], "category": "math"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"question": "Q?", "answers": ["A", "B"], "answer_key": 2, "category": "math"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"question": "Q?", "answers": ["A", "B"], "category": "cooking"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"question": "Q?", "answers": ["A", "B"], "category": "math", "difficulty": 9}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"type": "riddle", "question": "Q?", "answers": ["A", "B"], "category": "math"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"type": "numeric", "question": "Q?", "number": 3, "tolerance": -1, "category": "math"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"type": "text", "question": "Q?", "category": "math"}]`, ErrInvalidChallenge},
+		{"pack.json", `[{"type": "order", "question": "Q?", "answers": ["A", "B", "A"], "category": "math"}]`, ErrInvalidChallenge},
+	}
+	for _, tc := range errorCases {
+		if _, err := ParseChallengePack(tc.name, []byte(tc.data)); !errors.Is(err, tc.err) {
+			t.Errorf("%s %s: expected %v, got %v", tc.name, tc.data, tc.err, err)
+		}
+	}
+}
+
+func TestEncodeChallengePack(t *testing.T) {
+	challenges, err := ParseChallengePack("rivers.yaml", []byte(testYAMLPack))
+	if err != nil {
+		t.Fatalf("Expected a valid YAML pack, got %v", err)
+	}
+
+	for _, name := range []string{"rivers.json", "rivers.yml"} {
+		data, err := EncodeChallengePack(name, challenges)
+		if err != nil {
+			t.Fatalf("%s: failed to encode: %v", name, err)
+		}
+		decoded, err := ParseChallengePack(name, data)
+		if err != nil {
+			t.Fatalf("%s: failed to parse the encoded pack: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, challenges) {
+			t.Errorf("%s: expected %+v, got %+v", name, challenges, decoded)
+		}
+	}
+
+	if _, err := EncodeChallengePack("rivers.txt", challenges); !errors.Is(err, ErrUnknownPackFormat) {
+		t.Errorf("Expected ErrUnknownPackFormat, got %v", err)
+	}
+}
+
+func TestLoadChallengePacks(t *testing.T) {
+	embedded, err := defaultChallenges()
+	if err != nil {
+		t.Fatalf("Failed to load the embedded challenges: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeTestPack(t, dir, "rivers.yml", testYAMLPack)
+	writeTestPack(t, dir, "math.json", testJSONPack)
+	writeTestPack(t, dir, "notes.txt", "not a pack")
+
+	challenges, err := LoadChallengePacks(dir)
+	if err != nil {
+		t.Fatalf("Expected valid packs, got %v", err)
+	}
+	if len(challenges) != len(embedded)+2 {
+		t.Fatalf("Expected %d challenges, got %d", len(embedded)+2, len(challenges))
+	}
+	if challenges[len(embedded)].Category != common.CategoryMath {
+		t.Errorf("Expected the packs in name order, got %+v", challenges[len(embedded)])
+	}
+
+	writeTestPack(t, dir, "broken.json", "[{")
+	if _, err := LoadChallengePacks(dir); err == nil {
+		t.Error("Expected an invalid pack to fail the load")
+	}
+	if _, err := LoadChallengePacks(filepath.Join(dir, "missing")); !errors.Is(err, ErrInvalidChallengeDir) {
+		t.Errorf("Expected ErrInvalidChallengeDir, got %v", err)
+	}
+}
+
+func TestChallengeWatcher(t *testing.T) {
+	previous, _ := Challenges()
+	t.Cleanup(func() { SetChallenges(previous) })
+	embedded, _ := defaultChallenges()
+
+	dir := t.TempDir()
+	watcher, err := NewChallengeWatcher(dir)
+	if err != nil {
+		t.Fatalf("Expected an empty directory to load, got %v", err)
+	}
+	if changed, err := watcher.Reload(); changed || err != nil {
+		t.Errorf("Expected no reload without changes, got %v (%v)", changed, err)
+	}
+
+	// A room created before the reload keeps its challenges
+	before, err := NewChallengeManager()
+	if err != nil {
+		t.Fatalf("Failed to create ChallengeManager: %v", err)
+	}
+
+	writeTestPack(t, dir, "rivers.yaml", testYAMLPack)
+	if changed, err := watcher.Reload(); !changed || err != nil {
+		t.Fatalf("Expected the new pack to be loaded, got %v (%v)", changed, err)
+	}
+	if challenges, _ := Challenges(); len(challenges) != len(embedded)+1 {
+		t.Errorf("Expected %d challenges, got %d", len(embedded)+1, len(challenges))
+	}
+	if len(before.challenges) != len(embedded) {
+		t.Errorf("Expected the running room to keep %d challenges, got %d", len(embedded), len(before.challenges))
+	}
+
+	// An invalid pack keeps the pool
+	writeTestPack(t, dir, "broken.json", "[{")
+	if _, err := watcher.Reload(); err == nil {
+		t.Error("Expected the invalid pack to be reported")
+	}
+	if challenges, _ := Challenges(); len(challenges) != len(embedded)+1 {
+		t.Errorf("Expected the pool to be kept, got %d challenges", len(challenges))
+	}
+}
+
+// writeTestPack writes a challenge pack in dir.
+func writeTestPack(t *testing.T, dir, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
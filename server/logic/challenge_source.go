@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	"Goonker/common"
+)
+
+// Shares of the challenges of the rooms not coming from the challenge pool
+const (
+	DefaultGeneratedChallengeRatio = 0.3
+	DefaultMinigameRatio           = 0.2
+)
+
+// ChallengeMix holds the shares of the generated challenges and the minigames in a room, from 0 to 1.
+// The challenge pool provides the rest.
+type ChallengeMix struct {
+	Generated float64
+	Minigames float64
+}
+
+// ChallengeSource provides the challenges asked in a room.
+type ChallengeSource interface {
+	// SetFilter restricts the challenges picked to the ones allowed by filter.
+	SetFilter(filter common.ChallengeFilter) error
+
+	// PickChallenge returns a challenge, copied so that it can be shuffled.
+	PickChallenge() (*Challenge, error)
+}
+
+// MixedChallengeSource picks challenges from several sources, each one with its weight.
+// Sources without challenges allowed by the filter are skipped.
+type MixedChallengeSource struct {
+	sources []ChallengeSource
+	weights []float64
+	active  []bool
+	rng     *rand.Rand
+}
+
+// NewMixedChallengeSource creates a source picking from sources in proportion to weights.
+func NewMixedChallengeSource(rng *rand.Rand, sources []ChallengeSource, weights []float64) *MixedChallengeSource {
+	active := make([]bool, len(sources))
+	for i := range active {
+		active[i] = true
+	}
+	return &MixedChallengeSource{sources: sources, weights: weights, active: active, rng: rng}
+}
+
+// NewChallengeSource creates the challenge source of a room, the challenge pool
+// mixed with generated challenges and minigames as given by mix, restricted to filter.
+func NewChallengeSource(filter common.ChallengeFilter, mix ChallengeMix) (ChallengeSource, error) {
+	pool, err := NewChallengeManager()
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	generated, minigames := min(max(mix.Generated, 0), 1), min(max(mix.Minigames, 0), 1)
+	candidates := []struct {
+		source ChallengeSource
+		weight float64
+	}{
+		{pool, max(1-generated-minigames, 0)},
+		{NewChallengeGenerator(rng), generated},
+		{NewMinigameGenerator(rng), minigames},
+	}
+
+	var sources []ChallengeSource
+	var weights []float64
+	for _, candidate := range candidates {
+		if candidate.weight > 0 {
+			sources, weights = append(sources, candidate.source), append(weights, candidate.weight)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, ErrNoChallenges
+	}
+
+	source := NewMixedChallengeSource(rng, sources, weights)
+	if err := source.SetFilter(filter); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// SetFilter sets the filter of every source, it fails if none has challenges allowed by filter.
+func (m *MixedChallengeSource) SetFilter(filter common.ChallengeFilter) error {
+	if err := ValidateChallengeFilter(filter); err != nil {
+		return err
+	}
+
+	active := make([]bool, len(m.sources))
+	found := false
+	for i, source := range m.sources {
+		err := source.SetFilter(filter)
+		if err != nil && !errors.Is(err, ErrNoChallenges) {
+			return err
+		}
+		active[i] = err == nil
+		found = found || active[i]
+	}
+	if !found {
+		return ErrNoChallenges
+	}
+
+	m.active = active
+	return nil
+}
+
+// PickChallenge picks a challenge from one of the active sources, drawn by weight.
+func (m *MixedChallengeSource) PickChallenge() (*Challenge, error) {
+	total := 0.0
+	for i, weight := range m.weights {
+		if m.active[i] {
+			total += weight
+		}
+	}
+	if total <= 0 {
+		return nil, ErrNoChallenges
+	}
+
+	draw := m.rng.Float64() * total
+	for i, weight := range m.weights {
+		if !m.active[i] {
+			continue
+		}
+		if draw < weight {
+			return m.sources[i].PickChallenge()
+		}
+		draw -= weight
+	}
+
+	// Rounding left the draw past the last weight
+	for i := len(m.sources) - 1; i >= 0; i-- {
+		if m.active[i] {
+			return m.sources[i].PickChallenge()
+		}
+	}
+	return nil, ErrNoChallenges
+}
@@ -1,8 +1,12 @@
 package logic
 
 import (
+	"errors"
 	"log"
+	"slices"
 	"testing"
+
+	"Goonker/common"
 )
 
 func TestShuffle(t *testing.T) {
@@ -66,3 +70,147 @@ func TestPickChallenge(t *testing.T) {
 		t.Error("Expected challenge, got nil")
 	}
 }
+
+func TestChallengeFilter(t *testing.T) {
+	cm := &ChallengeManager{challenges: []Challenge{
+		{Question: "Easy geography", Category: common.CategoryGeography, Difficulty: common.ChallengeEasy},
+		{Question: "Hard geography", Category: common.CategoryGeography, Difficulty: common.ChallengeHard},
+		{Question: "Easy math", Category: common.CategoryMath, Difficulty: common.ChallengeEasy},
+	}}
+
+	filter := common.ChallengeFilter{Categories: []string{common.CategoryGeography}, MaxDifficulty: common.ChallengeMedium}
+	if err := cm.SetFilter(filter); err != nil {
+		t.Fatalf("Expected a valid filter, got %v", err)
+	}
+	for range 10 {
+		challenge, err := cm.PickChallenge()
+		if err != nil || challenge.Question != "Easy geography" {
+			t.Fatalf("Expected the only matching challenge, got %+v (%v)", challenge, err)
+		}
+	}
+
+	errorCases := []struct {
+		filter common.ChallengeFilter
+		err    error
+	}{
+		{common.ChallengeFilter{Categories: []string{"cooking"}}, ErrUnknownCategory},
+		{common.ChallengeFilter{MinDifficulty: common.ChallengeHard, MaxDifficulty: common.ChallengeEasy}, ErrInvalidDifficulty},
+		{common.ChallengeFilter{Categories: []string{common.CategoryMath}, MinDifficulty: common.ChallengeMedium}, ErrNoChallenges},
+	}
+	for _, tc := range errorCases {
+		if err := cm.SetFilter(tc.filter); !errors.Is(err, tc.err) {
+			t.Errorf("Filter %+v: expected %v, got %v", tc.filter, tc.err, err)
+		}
+	}
+}
+
+func TestChallengesMetadata(t *testing.T) {
+	cm, err := NewChallengeManager()
+	if err != nil {
+		t.Fatalf("Failed to create ChallengeManager: %v", err)
+	}
+
+	for _, challenge := range cm.challenges {
+		if !slices.Contains(common.Categories, challenge.Category) {
+			t.Errorf("%q: unknown category '%s'", challenge.Question, challenge.Category)
+		}
+		if challenge.Difficulty < common.ChallengeEasy || challenge.Difficulty > common.ChallengeHard {
+			t.Errorf("%q: invalid difficulty %d", challenge.Question, challenge.Difficulty)
+		}
+		if challenge.Language != DefaultChallengeLanguage {
+			t.Errorf("%q: expected language '%s', got '%s'", challenge.Question, DefaultChallengeLanguage, challenge.Language)
+		}
+	}
+}
+
+func TestGradeChallenge(t *testing.T) {
+	number := func(n float64) *float64 { return &n }
+
+	trueFalse := Challenge{Type: common.ChallengeTrueFalse, Correct: false}
+	trueFalse.setDefaults()
+	numeric := Challenge{Type: common.ChallengeNumeric, Number: 1.41, Tolerance: 0.005}
+	text := Challenge{Type: common.ChallengeText, Answers: []string{"Zürich", "Saint-Gall"}}
+	order := Challenge{Type: common.ChallengeOrder, Answers: []string{"A", "B", "C"}}
+	order.Shuffle()
+
+	// Indices of the shuffled items in the expected order
+	var expected []int
+	for _, item := range []string{"A", "B", "C"} {
+		expected = append(expected, slices.Index(order.Answers, item))
+	}
+
+	testCases := []struct {
+		name      string
+		challenge Challenge
+		answer    common.AnswerPayload
+		correct   bool
+	}{
+		{"true/false", trueFalse, common.AnswerPayload{Answer: 1}, true},
+		{"true/false wrong", trueFalse, common.AnswerPayload{Answer: 0}, false},
+		{"numeric", numeric, common.AnswerPayload{Number: number(1.414)}, true},
+		{"numeric outside tolerance", numeric, common.AnswerPayload{Number: number(1.42)}, false},
+		{"numeric without answer", numeric, common.AnswerPayload{Answer: common.NoAnswer}, false},
+		{"text", text, common.AnswerPayload{Text: "  zurich"}, true},
+		{"text punctuation", text, common.AnswerPayload{Text: "saint gall!"}, true},
+		{"text wrong", text, common.AnswerPayload{Text: "Geneva"}, false},
+		{"text empty", text, common.AnswerPayload{Text: "?"}, false},
+		{"order", order, common.AnswerPayload{Order: expected}, true},
+		{"order as sent", order, common.AnswerPayload{Order: []int{0, 1, 2}}, false},
+		{"order incomplete", order, common.AnswerPayload{Order: expected[:2]}, false},
+		{"order out of range", order, common.AnswerPayload{Order: []int{3, 4, 5}}, false},
+	}
+	for _, tc := range testCases {
+		if correct := tc.challenge.Grade(tc.answer, 0); correct != tc.correct {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.correct, correct)
+		}
+	}
+
+	// Only choices are sent for choice-like and ordering challenges
+	if payload := numeric.Payload(); payload.Type != common.ChallengeNumeric || payload.Answers != nil {
+		t.Errorf("Expected a numeric payload without answers, got %+v", payload)
+	}
+	if payload := text.Payload(); payload.Answers != nil {
+		t.Errorf("Expected the accepted answers to be hidden, got %+v", payload)
+	}
+	if payload := order.Payload(); len(payload.Answers) != 3 {
+		t.Errorf("Expected the items to order, got %+v", payload)
+	}
+}
+
+func TestChallengeSolution(t *testing.T) {
+	choice := Challenge{Question: "Capital of Switzerland?", Answers: []string{"Bern", "Zurich", "Geneva"}}
+	choice.setDefaults()
+	choice.Shuffle()
+	order := Challenge{Type: common.ChallengeOrder, Answers: []string{"A", "B", "C"}}
+	order.Shuffle()
+	trueFalse := Challenge{Type: common.ChallengeTrueFalse, Correct: false}
+	trueFalse.setDefaults()
+
+	testCases := []struct {
+		name      string
+		challenge Challenge
+		solution  string
+	}{
+		{"choice", choice, "Bern"},
+		{"true/false", trueFalse, "False"},
+		{"numeric", Challenge{Type: common.ChallengeNumeric, Number: 1.41}, "1.41"},
+		{"text", Challenge{Type: common.ChallengeText, Answers: []string{"Zürich", "Zurich"}}, "Zürich"},
+		{"order", order, "A, B, C"},
+		{"minigame", Challenge{Type: common.ChallengeMinigame}, ""},
+	}
+	for _, tc := range testCases {
+		if solution := tc.challenge.Solution(); solution != tc.solution {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.solution, solution)
+		}
+	}
+}
+
+func TestShuffleOrder(t *testing.T) {
+	for range 20 {
+		c := Challenge{Type: common.ChallengeOrder, Answers: []string{"A", "B"}}
+		c.Shuffle()
+		if !slices.Equal(c.Answers, []string{"B", "A"}) {
+			t.Fatalf("Expected the items never to be sent in the expected order, got %v", c.Answers)
+		}
+	}
+}
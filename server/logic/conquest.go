@@ -0,0 +1,171 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"Goonker/common"
+)
+
+// DefaultConquestCooldown is the number of turns a cell is safe after a failed conquest, with the cooldown penalty.
+const DefaultConquestCooldown = 2
+
+// Error messages
+var (
+	ErrUnknownPenalty      = errors.New("unknown conquest penalty")
+	ErrInvalidConquestRule = errors.New("invalid conquest rules")
+	ErrConquestForbidden   = errors.New("cell can not be conquered")
+)
+
+// ConquestLogic enforces the conquest rules of a room on top of a game variant.
+// The conquests made and the cooldowns are read from the history, so that they follow takebacks.
+type ConquestLogic struct {
+	Rules
+	Conquests common.ConquestRules
+}
+
+// WithConquestRules returns game enforcing the conquest rules, game itself for the default rules.
+func WithConquestRules(game Rules, conquests common.ConquestRules) (Rules, error) {
+	if err := ValidateConquestRules(game, conquests); err != nil {
+		return nil, err
+	}
+
+	if (conquests.Penalty == "" || conquests.Penalty == common.PenaltyTurn) && conquests.MaxConquests == 0 && len(conquests.Protected) == 0 {
+		return game, nil
+	}
+	if conquests.Penalty == common.PenaltyCooldown && conquests.Cooldown == 0 {
+		conquests.Cooldown = DefaultConquestCooldown
+	}
+	return &ConquestLogic{Rules: game, Conquests: conquests}, nil
+}
+
+// ValidateConquestRules checks the penalty and the limits of conquests, and that the protected cells are on the board of game.
+func ValidateConquestRules(game Rules, conquests common.ConquestRules) error {
+	if conquests.Penalty != "" && !slices.Contains(common.Penalties, conquests.Penalty) {
+		return fmt.Errorf("%w: '%s'", ErrUnknownPenalty, conquests.Penalty)
+	}
+	if conquests.Cooldown < 0 || conquests.MaxConquests < 0 {
+		return fmt.Errorf("%w: negative cooldown or limit", ErrInvalidConquestRule)
+	}
+
+	state := game.State()
+	for _, cell := range conquests.Protected {
+		if cell.X < 0 || cell.X >= state.Width || cell.Y < 0 || cell.Y >= state.Height {
+			return fmt.Errorf("%w: protected cell (%d, %d) is off the board", ErrInvalidConquestRule, cell.X, cell.Y)
+		}
+	}
+	return nil
+}
+
+// ConquestRulesOf returns the conquest rules enforced by game, the zero value for the default rules.
+func ConquestRulesOf(game Rules) common.ConquestRules {
+	if conquest, ok := game.(*ConquestLogic); ok {
+		return conquest.Conquests
+	}
+	return common.ConquestRules{}
+}
+
+// Hash returns the hash of the wrapped position, including the conquests made and the cells cooling down.
+// Every variant is a Hasher.
+func (c *ConquestLogic) Hash() uint64 {
+	hash := c.Rules.(Hasher).Hash()
+
+	conquered := map[common.PlayerID]int{}
+	history := c.History()
+	for i, move := range history {
+		if move.Conquest && move.Won && c.Conquests.MaxConquests > 0 {
+			conquered[move.Player]++
+		}
+
+		// Plies left before the cell can be attacked again, as in canConquer
+		cooling := c.Conquests.Penalty == common.PenaltyCooldown && move.Conquest && !move.Won
+		if left := 2*c.Conquests.Cooldown - (len(history) - i); cooling && left >= 0 {
+			hash ^= mixHash(1<<62 | uint64(move.X)<<40 | uint64(move.Y)<<24 | uint64(left))
+		}
+	}
+	for player, count := range conquered {
+		hash ^= mixHash(1<<63 | uint64(player)<<32 | uint64(count))
+	}
+	return hash
+}
+
+// Evaluate scores the wrapped position for player.
+func (c *ConquestLogic) Evaluate(player common.PlayerID) int {
+	return evaluate(c.Rules, player)
+}
+
+// Clone returns a deep copy of the game, with the same conquest rules.
+func (c *ConquestLogic) Clone() Rules {
+	return &ConquestLogic{Rules: c.Rules.Clone(), Conquests: c.Conquests}
+}
+
+// ShouldTriggerChallenge checks if the move is a conquest allowed by the rules.
+func (c *ConquestLogic) ShouldTriggerChallenge(player common.PlayerID, x, y int) bool {
+	return c.Rules.ShouldTriggerChallenge(player, x, y) && c.canConquer(player, x, y, c.History())
+}
+
+// ConquestMoves lists the opponent cells the current player may try to conquer under the rules.
+func (c *ConquestLogic) ConquestMoves() []Move {
+	player, history := c.CurrentTurn(), c.History()
+	return slices.DeleteFunc(c.Rules.ConquestMoves(), func(move Move) bool {
+		return !c.canConquer(player, move.X, move.Y, history)
+	})
+}
+
+// ApplyMove plays a move, refusing the conquests forbidden by the rules.
+// A won conquest emptied the cell first, so a move still targeting an opponent cell is a failed conquest, punished with the penalty.
+func (c *ConquestLogic) ApplyMove(player common.PlayerID, x, y int) error {
+	failedConquest := c.Rules.ShouldTriggerChallenge(player, x, y)
+	if failedConquest && !c.canConquer(player, x, y, c.History()) {
+		return ErrConquestForbidden
+	}
+	if err := c.Rules.ApplyMove(player, x, y); err != nil {
+		return err
+	}
+
+	if !failedConquest || c.Conquests.Penalty != common.PenaltyCell {
+		return nil
+	}
+	if _, over := c.Outcome(); over {
+		return nil
+	}
+	if cell, ok := c.lastCell(player); ok {
+		return c.ForfeitCell(cell.X, cell.Y)
+	}
+	return nil
+}
+
+// canConquer checks if the rules let player attack the cell at (x, y), given the history of the game.
+func (c *ConquestLogic) canConquer(player common.PlayerID, x, y int, history []MoveRecord) bool {
+	if slices.Contains(c.Conquests.Protected, common.Cell{X: x, Y: y}) {
+		return false
+	}
+
+	conquered := 0
+	for i, move := range history {
+		if move.Conquest && move.Won && move.Player == player {
+			conquered++
+		}
+
+		// The attacker plays every other move, a cell attacked in vain is safe for its next Cooldown turns
+		if c.Conquests.Penalty == common.PenaltyCooldown && move.Conquest && !move.Won &&
+			move.X == x && move.Y == y && len(history)-i <= 2*c.Conquests.Cooldown {
+			return false
+		}
+	}
+	return c.Conquests.MaxConquests == 0 || conquered < c.Conquests.MaxConquests
+}
+
+// lastCell returns the cell player placed or conquered most recently and still owns, false if none.
+func (c *ConquestLogic) lastCell(player common.PlayerID) (Move, bool) {
+	board := c.State().Board
+	history := c.History()
+	for i := len(history) - 1; i >= 0; i-- {
+		move := history[i]
+		if move.Player == player && board[move.X][move.Y] == player {
+			return Move{X: move.X, Y: move.Y}, true
+		}
+	}
+	return Move{}, false
+}
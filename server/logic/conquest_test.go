@@ -0,0 +1,215 @@
+package logic
+
+import (
+	"Goonker/common"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newConquestGame creates a classic game with conquest rules, failing the test if they are invalid.
+func newConquestGame(t *testing.T, conquests common.ConquestRules) Rules {
+	t.Helper()
+	game, err := WithConquestRules(NewGameLogic(ClassicRules), conquests)
+	if err != nil {
+		t.Fatalf("Invalid conquest rules %+v: %v", conquests, err)
+	}
+	return game
+}
+
+func TestValidateConquestRules(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	invalid := []common.ConquestRules{
+		{Penalty: "jail"},
+		{MaxConquests: -1},
+		{Penalty: common.PenaltyCooldown, Cooldown: -2},
+		{Protected: []common.Cell{{X: 3, Y: 0}}},
+	}
+	for _, conquests := range invalid {
+		if _, err := WithConquestRules(game, conquests); err == nil {
+			t.Errorf("Expected %+v to be refused", conquests)
+		}
+	}
+
+	// The default rules keep the variant itself, so that the tablebase still applies
+	if rules, err := WithConquestRules(game, common.ConquestRules{Penalty: common.PenaltyTurn}); err != nil || rules != Rules(game) {
+		t.Errorf("Expected the default rules to keep the game, got %T, %v", rules, err)
+	}
+	rules, err := WithConquestRules(game, common.ConquestRules{Penalty: common.PenaltyCooldown})
+	if err != nil || ConquestRulesOf(rules).Cooldown != DefaultConquestCooldown {
+		t.Errorf("Expected the default cooldown, got %+v, %v", ConquestRulesOf(rules), err)
+	}
+}
+
+func TestProtectedCells(t *testing.T) {
+	game := newConquestGame(t, common.ConquestRules{Protected: []common.Cell{{X: 1, Y: 1}}})
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 0, 0)
+
+	if game.ShouldTriggerChallenge(common.P1, 1, 1) || !game.ShouldTriggerChallenge(common.P1, 0, 0) {
+		t.Error("Expected only the protected cell to be safe")
+	}
+	mustApply(t, game, common.P1, 2, 2)
+	if err := game.ApplyMove(common.P2, 1, 1); !errors.Is(err, ErrConquestForbidden) {
+		t.Errorf("Expected ErrConquestForbidden, got %v", err)
+	}
+	if game.CurrentTurn() != common.P2 {
+		t.Error("Expected a forbidden conquest to keep the turn")
+	}
+	for _, move := range game.ConquestMoves() {
+		if move.X == 1 && move.Y == 1 {
+			t.Error("Expected the protected cell not to be a conquest move")
+		}
+	}
+}
+
+func TestConquestLimit(t *testing.T) {
+	game := newConquestGame(t, common.ConquestRules{MaxConquests: 1})
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+
+	// Won conquest of the center
+	game.DeleteMove(1, 1)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 2, 2)
+
+	if game.ShouldTriggerChallenge(common.P1, 2, 2) || len(game.ConquestMoves()) != 0 {
+		t.Error("Expected X to have no conquest left")
+	}
+	if !game.ShouldTriggerChallenge(common.P2, 0, 0) {
+		t.Error("Expected O to keep its conquest")
+	}
+
+	// Taking the conquest back gives it back
+	for range 2 {
+		if err := game.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !game.ShouldTriggerChallenge(common.P1, 1, 1) {
+		t.Error("Expected the conquest to be available again after the takeback")
+	}
+}
+
+func TestConquestCooldown(t *testing.T) {
+	game := newConquestGame(t, common.ConquestRules{Penalty: common.PenaltyCooldown, Cooldown: 1})
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+	mustApply(t, game, common.P1, 1, 1) // Lost conquest
+	mustApply(t, game, common.P2, 2, 0)
+
+	if game.ShouldTriggerChallenge(common.P1, 1, 1) || !game.ShouldTriggerChallenge(common.P1, 2, 0) {
+		t.Error("Expected only the cell attacked in vain to cool down")
+	}
+	mustApply(t, game, common.P1, 0, 2)
+	mustApply(t, game, common.P2, 2, 2)
+	if !game.ShouldTriggerChallenge(common.P1, 1, 1) {
+		t.Error("Expected the cooldown to be over after one turn")
+	}
+}
+
+func TestConquestCellPenalty(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		moves    []Move // Alternate moves from X, the last one a lost conquest of X on b2
+		lost     Move   // Last cell placed by X
+		notation string
+	}{
+		{common.ModeClassic, []Move{{0, 0}, {1, 1}, {2, 0}, {0, 2}, {1, 1}}, Move{X: 2, Y: 0}, "5. X b2? -c1"},
+		{common.ModeUltimate, []Move{{0, 0}, {1, 1}, {4, 4}, {3, 3}, {1, 1}}, Move{X: 4, Y: 4}, "5. X b2? -e5"},
+	}
+
+	for _, tc := range testCases {
+		variant, err := NewRules(tc.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		game, err := WithConquestRules(variant, common.ConquestRules{Penalty: common.PenaltyCell})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, move := range tc.moves {
+			mustApply(t, game, common.PlayerID(i%2)+common.P1, move.X, move.Y)
+		}
+
+		board := game.State().Board
+		if board[tc.lost.X][tc.lost.Y] != common.Empty || board[0][0] != common.P1 || board[1][1] != common.P2 {
+			t.Errorf("%s: expected X to lose its last cell, got %v", tc.mode, board)
+		}
+		history := game.History()
+		if forfeit := history[len(history)-1].Forfeit; forfeit == nil || *forfeit != tc.lost {
+			t.Errorf("%s: expected the lost cell in the history, got %v", tc.mode, forfeit)
+		}
+
+		// The record keeps the lost cell, and replays it
+		record := NewGameRecord(tc.mode, game, "Alice", "Bob")
+		if !strings.Contains(record.String(), tc.notation) {
+			t.Errorf("%s: expected the lost cell in the record, got:\n%s", tc.mode, record.String())
+		}
+		parsed, err := ParseGameRecord(strings.NewReader(record.String()))
+		if err != nil {
+			t.Fatalf("%s: failed to parse record: %v", tc.mode, err)
+		}
+		if replayed, err := parsed.Replay(nil); err != nil || replayed.State().Board[tc.lost.X][tc.lost.Y] != common.Empty {
+			t.Errorf("%s: expected the replay to lose the cell too, got %v", tc.mode, err)
+		}
+
+		// Undo gives the cell back
+		if err := game.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if board := game.State().Board; board[tc.lost.X][tc.lost.Y] != common.P1 || board[1][1] != common.P2 {
+			t.Errorf("%s: expected the lost cell back after undo, got %v", tc.mode, board)
+		}
+	}
+}
+
+func TestConquestLogicSearch(t *testing.T) {
+	// Rules that change nothing yet, so that the bot must play as on the plain variant
+	conquests := common.ConquestRules{MaxConquests: 5}
+	options := SearchOptions{MaxDepth: 3, ConquestProbability: 0.5}
+
+	for _, mode := range []string{common.ModeClassic, common.ModeUltimate} {
+		plain, err := NewRules(mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustApply(t, plain, common.P1, 0, 0)
+		mustApply(t, plain, common.P2, 1, 1)
+		wrapped, err := WithConquestRules(plain.Clone(), conquests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := wrapped.(*ConquestLogic); !ok {
+			t.Fatalf("%s: expected the game to be wrapped, got %T", mode, wrapped)
+		}
+
+		expected := NewSearcher(options).Search(context.Background(), plain)
+		result := NewSearcher(options).Search(context.Background(), wrapped)
+		// The hash of the wrapped game tells apart the conquests made, so fewer positions transpose
+		result.Nodes = expected.Nodes
+		if result != expected {
+			t.Errorf("%s: expected the wrapped game to be searched like the plain one, got %+v instead of %+v", mode, result, expected)
+		}
+
+		// The tablebase is solved under the default rules only
+		if _, ok := DefaultTablebase().Lookup(wrapped); ok {
+			t.Errorf("%s: expected the tablebase to refuse the conquest rules", mode)
+		}
+	}
+
+	// The transposition table tells apart positions with different conquests left
+	game := newConquestGame(t, common.ConquestRules{MaxConquests: 1})
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+	before := game.(Hasher).Hash()
+	game.DeleteMove(1, 1)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 2, 2)
+	mustApply(t, game, common.P1, 0, 2)
+	conquered := game.(Hasher).Hash()
+	if game.(*ConquestLogic).Rules.(Hasher).Hash() == conquered || before == conquered {
+		t.Error("Expected the conquests made to change the hash")
+	}
+}
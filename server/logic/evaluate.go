@@ -0,0 +1,84 @@
+package logic
+
+import "Goonker/common"
+
+// Evaluation constants
+const (
+	// Weight growth for each extra symbol in an open line
+	lineWeightShift = 3
+
+	// Weight of a sub-board line relative to a cell line in Ultimate
+	subBoardLineWeight = 64
+
+	// Evaluations are clamped so that they never reach a proven result
+	MaxEvaluation = WinScore / 2
+)
+
+// Evaluator is implemented by the variants able to estimate an unfinished position.
+type Evaluator interface {
+	// Evaluate scores the position for player, positive values favor player.
+	Evaluate(player common.PlayerID) int
+}
+
+// lineWeight is the value of an open line holding count symbols of a single player.
+func lineWeight(count int) int {
+	return 1 << (lineWeightShift * (count - 1))
+}
+
+// scoreLines sums the open lines of length k inside the w x h block of board starting at (minX, minY).
+// A line is open for a player when the opponent has no symbol in it.
+func scoreLines(board [][]common.PlayerID, minX, minY, w, h, k int, player common.PlayerID) int {
+	score := 0
+	for x := minX; x < minX+w; x++ {
+		for y := minY; y < minY+h; y++ {
+			for _, dir := range directions {
+				// The line must end inside the block
+				endX, endY := x+(k-1)*dir[0], y+(k-1)*dir[1]
+				if endX < minX || endX >= minX+w || endY < minY || endY >= minY+h {
+					continue
+				}
+
+				mine, theirs := 0, 0
+				for i := range k {
+					switch board[x+i*dir[0]][y+i*dir[1]] {
+					case common.Empty:
+					case player:
+						mine++
+					default:
+						theirs++
+					}
+				}
+
+				if theirs == 0 && mine > 0 {
+					score += lineWeight(mine)
+				} else if mine == 0 && theirs > 0 {
+					score -= lineWeight(theirs)
+				}
+			}
+		}
+	}
+	return score
+}
+
+// clampEvaluation keeps an evaluation below the score of a proven result.
+func clampEvaluation(score int) int {
+	return max(-MaxEvaluation, min(MaxEvaluation, score))
+}
+
+// Evaluate scores the position with the lines still open for each player.
+func (g *GameLogic) Evaluate(player common.PlayerID) int {
+	return clampEvaluation(scoreLines(g.Board, 0, 0, g.Rules.Width, g.Rules.Height, g.Rules.WinLength, player))
+}
+
+// Evaluate scores the position with the open lines of won sub-boards and of the undecided sub-boards.
+func (u *UltimateLogic) Evaluate(player common.PlayerID) int {
+	score := subBoardLineWeight * scoreLines(u.SubBoards, 0, 0, SubBoardSize, SubBoardSize, SubBoardSize, player)
+	for sx := range SubBoardSize {
+		for sy := range SubBoardSize {
+			if u.isSubBoardOpen(sx, sy) {
+				score += scoreLines(u.Board, sx*SubBoardSize, sy*SubBoardSize, SubBoardSize, SubBoardSize, SubBoardSize, player)
+			}
+		}
+	}
+	return clampEvaluation(score)
+}
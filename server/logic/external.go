@@ -0,0 +1,348 @@
+package logic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Goonker/common"
+)
+
+// External bot protocol
+//
+// The server talks to the bot process with one command per line on its standard input,
+// the bot replies on its standard output. Cells use the record notation (a1 is x=0, y=0).
+//
+//	newgame <mode> <width> <height> <X|O>   A game starts, the bot plays the given symbol
+//	position <X|O> <row>/<row>/...          Player to move and board rows from y=0, cells are x, o or .
+//	legal <cell> <cell> ...                 Empty cells the bot may claim
+//	conquests <cell> <cell> ...             Opponent cells the bot may try to conquer
+//	go                                      The bot must reply "move <cell>"
+//	challenge <count> <question>            Followed by count "option <text>" lines,
+//	                                        the bot must reply "answer <index>" (0 based)
+//	quit                                    The game is over, the bot should exit
+//
+// Lines of the bot starting with "info" are logged and ignored.
+const (
+	// Time a bot has to reply to a command
+	ExternalBotTimeout = 5 * time.Second
+
+	// Time a bot has to exit after quit before it is killed
+	ExternalBotQuitTimeout = time.Second
+
+	// Protocol commands and replies
+	CmdNewGame   = "newgame"
+	CmdPosition  = "position"
+	CmdLegal     = "legal"
+	CmdConquests = "conquests"
+	CmdGo        = "go"
+	CmdChallenge = "challenge"
+	CmdOption    = "option"
+	CmdQuit      = "quit"
+	ReplyMove    = "move"
+	ReplyAnswer  = "answer"
+	ReplyInfo    = "info"
+)
+
+// Error messages
+var (
+	ErrNoBotCommand    = errors.New("no external bot command configured")
+	ErrBotExited       = errors.New("external bot exited")
+	ErrBotTimeout      = errors.New("external bot did not reply in time")
+	ErrBotInvalidReply = errors.New("invalid reply from external bot")
+)
+
+// ChallengeAnswerer is implemented by the bots answering challenges themselves.
+type ChallengeAnswerer interface {
+	// AnswerChallenge returns the index of the chosen answer, or an error if ctx is done first.
+	AnswerChallenge(ctx context.Context, question string, answers []string) (int, error)
+}
+
+// ExternalBot drives a bot running in another process with the line protocol.
+// When the process crashes, times out or replies nonsense, the fallback bot plays instead.
+// A request cancelled by its context also stops the process, whose reply would come out of turn.
+type ExternalBot struct {
+	Timeout time.Duration
+
+	mutex    sync.Mutex
+	mode     string
+	fallback Bot
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string // Lines of the bot, closed when it exits
+	started  bool        // newgame was sent
+	dead     bool
+}
+
+// NewExternalBot starts the bot process of command, the first element being the executable.
+func NewExternalBot(command []string, mode string, fallback Bot) (*ExternalBot, error) {
+	if len(command) == 0 {
+		return nil, ErrNoBotCommand
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start external bot: %w", err)
+	}
+
+	bot := &ExternalBot{
+		Timeout:  ExternalBotTimeout,
+		mode:     mode,
+		fallback: fallback,
+		cmd:      cmd,
+		stdin:    stdin,
+		lines:    make(chan string),
+	}
+
+	// Forward the output of the bot until it exits
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			bot.lines <- strings.TrimSpace(scanner.Text())
+		}
+		close(bot.lines)
+		cmd.Wait()
+	}()
+
+	return bot, nil
+}
+
+// NextMove asks the bot process for a move, or the fallback bot if the process failed.
+func (b *ExternalBot) NextMove(ctx context.Context, game Rules) Move {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.dead {
+		move, err := b.requestMove(ctx, game)
+		if err == nil {
+			return move
+		}
+		b.fail(err)
+	}
+	return b.fallback.NextMove(ctx, game)
+}
+
+// AnswerChallenge asks the bot process to answer a challenge.
+func (b *ExternalBot) AnswerChallenge(ctx context.Context, question string, answers []string) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.dead {
+		return 0, ErrBotExited
+	}
+
+	answer, err := b.requestAnswer(ctx, question, answers)
+	if err != nil {
+		b.fail(err)
+		return 0, err
+	}
+	return answer, nil
+}
+
+// Close tells the bot that the game is over, and kills it if it does not exit.
+func (b *ExternalBot) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.dead {
+		return nil
+	}
+	b.dead = true
+
+	b.send(CmdQuit)
+	b.stdin.Close()
+
+	// Wait for the end of the output, then kill the bot if it is still running
+	timeout := time.After(ExternalBotQuitTimeout)
+	for {
+		select {
+		case _, ok := <-b.lines:
+			if !ok {
+				return nil
+			}
+		case <-timeout:
+			b.drain()
+			return b.cmd.Process.Kill()
+		}
+	}
+}
+
+// requestMove sends the position and reads the move of the bot.
+func (b *ExternalBot) requestMove(ctx context.Context, game Rules) (Move, error) {
+	state := game.State()
+	if !b.started {
+		if err := b.send(CmdNewGame, b.mode, strconv.Itoa(state.Width), strconv.Itoa(state.Height), playerSymbol(game.CurrentTurn())); err != nil {
+			return Move{}, err
+		}
+		b.started = true
+	}
+
+	legal := game.LegalMoves()
+	conquests := game.ConquestMoves()
+	commands := [][]string{
+		{CmdPosition, playerSymbol(state.Turn), FormatBoard(state.Board)},
+		append([]string{CmdLegal}, formatCells(legal)...),
+		append([]string{CmdConquests}, formatCells(conquests)...),
+		{CmdGo},
+	}
+	for _, command := range commands {
+		if err := b.send(command...); err != nil {
+			return Move{}, err
+		}
+	}
+
+	reply, err := b.receive(ctx, ReplyMove)
+	if err != nil {
+		return Move{}, err
+	}
+	move, err := ParseCell(reply)
+	if err != nil {
+		return Move{}, fmt.Errorf("%w: %v", ErrBotInvalidReply, err)
+	}
+
+	// Only legal moves and conquests are accepted
+	for _, candidate := range append(legal, conquests...) {
+		if candidate == move {
+			return move, nil
+		}
+	}
+	return Move{}, fmt.Errorf("%w: illegal move %s", ErrBotInvalidReply, reply)
+}
+
+// requestAnswer sends a challenge and reads the answer of the bot.
+func (b *ExternalBot) requestAnswer(ctx context.Context, question string, answers []string) (int, error) {
+	if err := b.send(CmdChallenge, strconv.Itoa(len(answers)), oneLine(question)); err != nil {
+		return 0, err
+	}
+	for _, answer := range answers {
+		if err := b.send(CmdOption, oneLine(answer)); err != nil {
+			return 0, err
+		}
+	}
+
+	reply, err := b.receive(ctx, ReplyAnswer)
+	if err != nil {
+		return 0, err
+	}
+	answer, err := strconv.Atoi(reply)
+	if err != nil || answer < 0 || answer >= len(answers) {
+		return 0, fmt.Errorf("%w: answer '%s'", ErrBotInvalidReply, reply)
+	}
+	return answer, nil
+}
+
+// send writes a command line to the bot.
+func (b *ExternalBot) send(fields ...string) error {
+	_, err := fmt.Fprintln(b.stdin, strings.Join(fields, " "))
+	return err
+}
+
+// receive waits for a reply starting with keyword and returns the rest of the line.
+func (b *ExternalBot) receive(ctx context.Context, keyword string) (string, error) {
+	timeout := time.After(b.Timeout)
+	for {
+		select {
+		case line, ok := <-b.lines:
+			if !ok {
+				return "", ErrBotExited
+			}
+
+			word, rest, _ := strings.Cut(line, " ")
+			switch word {
+			case keyword:
+				return strings.TrimSpace(rest), nil
+			case ReplyInfo, "":
+				if rest != "" {
+					log.Printf("External bot: %s", rest)
+				}
+			default:
+				return "", fmt.Errorf("%w: expected '%s', got '%s'", ErrBotInvalidReply, keyword, line)
+			}
+		case <-timeout:
+			return "", ErrBotTimeout
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// fail stops using the bot process after an error.
+func (b *ExternalBot) fail(err error) {
+	log.Printf("External bot failed, using the fallback bot: %v", err)
+	b.dead = true
+	b.stdin.Close()
+	b.cmd.Process.Kill()
+	b.drain()
+}
+
+// drain discards the remaining output of the bot, so that the reader can exit.
+func (b *ExternalBot) drain() {
+	go func() {
+		for range b.lines {
+		}
+	}()
+}
+
+// playerSymbol returns the protocol symbol of a player.
+func playerSymbol(player common.PlayerID) string {
+	if player == common.P2 {
+		return SymbolP2
+	}
+	return SymbolP1
+}
+
+// FormatBoard writes a board as its rows from y=0 separated by '/', with the cells x, o and '.'.
+func FormatBoard(board [][]common.PlayerID) string {
+	if len(board) == 0 {
+		return ""
+	}
+
+	rows := make([]string, len(board[0]))
+	for y := range rows {
+		var row strings.Builder
+		for x := range board {
+			switch board[x][y] {
+			case common.P1:
+				row.WriteString(strings.ToLower(SymbolP1))
+			case common.P2:
+				row.WriteString(strings.ToLower(SymbolP2))
+			default:
+				row.WriteString(SymbolEmpty)
+			}
+		}
+		rows[y] = row.String()
+	}
+	return strings.Join(rows, "/")
+}
+
+// formatCells writes cells in the record notation.
+func formatCells(moves []Move) []string {
+	cells := make([]string, len(moves))
+	for i, move := range moves {
+		cells[i] = FormatCell(move.X, move.Y)
+	}
+	return cells
+}
+
+// oneLine replaces the line breaks of a text, so that it fits a protocol line.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
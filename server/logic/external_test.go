@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"Goonker/common"
+)
+
+// testBotEnv makes the test binary act as an external bot with the given behavior.
+const testBotEnv = "GOONKER_TEST_BOT"
+
+func TestMain(m *testing.M) {
+	if behavior := os.Getenv(testBotEnv); behavior != "" {
+		runTestBot(behavior)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runTestBot plays the first legal move and answers "right" when it is an option.
+// Other behaviors: "crash" exits on go, "hang" never replies, "illegal" plays outside the board.
+func runTestBot(behavior string) {
+	var legal []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		command, args, _ := strings.Cut(scanner.Text(), " ")
+		switch command {
+		case CmdLegal:
+			legal = strings.Fields(args)
+		case CmdGo:
+			switch behavior {
+			case "crash":
+				os.Exit(1)
+			case "hang":
+				time.Sleep(time.Hour)
+			case "illegal":
+				fmt.Println("move z9")
+			default:
+				fmt.Println("info thinking")
+				fmt.Println("move " + legal[0])
+			}
+		case CmdChallenge:
+			countText, _, _ := strings.Cut(args, " ")
+			count, _ := strconv.Atoi(countText)
+			answer := 0
+			for i := range count {
+				scanner.Scan()
+				if scanner.Text() == CmdOption+" right" {
+					answer = i
+				}
+			}
+			fmt.Printf("answer %d\n", answer)
+		case CmdQuit:
+			return
+		}
+	}
+}
+
+// fixedBot always plays the same move, it is used as fallback.
+type fixedBot struct {
+	move Move
+}
+
+func (b fixedBot) NextMove(ctx context.Context, game Rules) Move {
+	return b.move
+}
+
+// startTestBot starts the test binary as an external bot.
+func startTestBot(t *testing.T, behavior string) *ExternalBot {
+	t.Helper()
+	t.Setenv(testBotEnv, behavior)
+
+	bot, err := NewExternalBot([]string{os.Args[0]}, common.ModeClassic, fixedBot{Move{X: 2, Y: 2}})
+	if err != nil {
+		t.Fatalf("Failed to start the bot: %v", err)
+	}
+	t.Cleanup(func() { bot.Close() })
+	return bot
+}
+
+func TestExternalBotPlays(t *testing.T) {
+	bot := startTestBot(t, "play")
+	game := NewGameLogic(ClassicRules)
+
+	if move := bot.NextMove(context.Background(), game); move != (Move{X: 0, Y: 0}) {
+		t.Fatalf("Expected the first legal move a1, got %v", move)
+	}
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+
+	if move := bot.NextMove(context.Background(), game); move != (Move{X: 0, Y: 1}) {
+		t.Errorf("Expected the first legal move a2, got %v", move)
+	}
+
+	answer, err := bot.AnswerChallenge(context.Background(), "Which one?", []string{"wrong", "right", "wrong"})
+	if err != nil || answer != 1 {
+		t.Errorf("Expected answer 1, got %d (%v)", answer, err)
+	}
+
+	if err := bot.Close(); err != nil {
+		t.Errorf("Expected the bot to exit on quit: %v", err)
+	}
+}
+
+func TestExternalBotFallback(t *testing.T) {
+	for _, behavior := range []string{"crash", "hang", "illegal"} {
+		bot := startTestBot(t, behavior)
+		bot.Timeout = 100 * time.Millisecond
+		game := NewGameLogic(ClassicRules)
+
+		if move := bot.NextMove(context.Background(), game); move != (Move{X: 2, Y: 2}) {
+			t.Errorf("%s: expected the fallback move, got %v", behavior, move)
+		}
+
+		// The process is not used anymore
+		if _, err := bot.AnswerChallenge(context.Background(), "Q?", []string{"A", "B"}); !errors.Is(err, ErrBotExited) {
+			t.Errorf("%s: expected ErrBotExited, got %v", behavior, err)
+		}
+	}
+}
+
+func TestExternalBotCancelled(t *testing.T) {
+	bot := startTestBot(t, "hang")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The bot is given up before its timeout
+	start := time.Now()
+	if move := bot.NextMove(ctx, NewGameLogic(ClassicRules)); move != (Move{X: 2, Y: 2}) {
+		t.Errorf("Expected the fallback move, got %v", move)
+	}
+	if elapsed := time.Since(start); elapsed >= bot.Timeout {
+		t.Errorf("Expected the request to stop with the context, took %v", elapsed)
+	}
+}
+
+func TestExternalBotNoCommand(t *testing.T) {
+	if _, err := NewExternalBot(nil, common.ModeClassic, fixedBot{}); !errors.Is(err, ErrNoBotCommand) {
+		t.Errorf("Expected ErrNoBotCommand, got %v", err)
+	}
+}
+
+func TestFormatBoard(t *testing.T) {
+	game := NewGameLogic(BoardRules{Width: 3, Height: 2, WinLength: 3})
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 2, 1)
+
+	if board := FormatBoard(game.Board); board != "x../..o" {
+		t.Errorf("Expected x../..o, got %s", board)
+	}
+}
+
+func TestParseCell(t *testing.T) {
+	move, err := ParseCell("c10")
+	if err != nil || move != (Move{X: 2, Y: 9}) {
+		t.Errorf("Expected 2,9, got %v (%v)", move, err)
+	}
+
+	for _, cell := range []string{"", "a0", "A1", "1a", "b2!"} {
+		if _, err := ParseCell(cell); err == nil {
+			t.Errorf("Expected '%s' to be invalid", cell)
+		}
+	}
+}
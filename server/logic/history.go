@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"Goonker/common"
+)
+
+// Error messages
+var (
+	ErrNothingToUndo   = errors.New("no move to undo")
+	ErrConquestPending = errors.New("a conquest is in progress")
+)
+
+// MoveRecord is an entry of the move log of a game.
+type MoveRecord struct {
+	Player   common.PlayerID `json:"player"`
+	X        int             `json:"x"`
+	Y        int             `json:"y"`
+	Conquest bool            `json:"conquest"`          // The move targeted an opponent cell through a challenge
+	Won      bool            `json:"won"`               // Whether the challenge was won, only set for conquests
+	Previous common.PlayerID `json:"previous"`          // Owner of the cell before the move
+	Forfeit  *Move           `json:"forfeit,omitempty"` // Cell of the player emptied as the penalty of a lost conquest
+	Time     time.Time       `json:"time"`
+
+	// Variant specific state to restore on undo
+	activeX, activeY int
+}
+
+// moveHistory keeps the ordered move log of a game, it is embedded by the variants.
+type moveHistory struct {
+	moves []MoveRecord
+
+	// Cell emptied by DeleteMove, waiting for the conquering ApplyMove
+	deleted  *Move
+	deletedP common.PlayerID
+}
+
+// History returns a copy of the ordered move log.
+func (h *moveHistory) History() []MoveRecord {
+	return slices.Clone(h.moves)
+}
+
+// markDeleted remembers the cell emptied before a conquest.
+func (h *moveHistory) markDeleted(x, y int, owner common.PlayerID) {
+	h.deleted = &Move{X: x, Y: y}
+	h.deletedP = owner
+}
+
+// takeDeleted forgets the cell emptied before a conquest, and returns it with its owner, nil if none.
+func (h *moveHistory) takeDeleted() (*Move, common.PlayerID) {
+	deleted, owner := h.deleted, h.deletedP
+	h.deleted = nil
+	return deleted, owner
+}
+
+// markForfeit attaches a cell lost as a penalty to the last move, owner being its current owner.
+func (h *moveHistory) markForfeit(x, y int, owner common.PlayerID) error {
+	if len(h.moves) == 0 {
+		return ErrNotYourCell
+	}
+
+	last := &h.moves[len(h.moves)-1]
+	if owner != last.Player || last.Forfeit != nil {
+		return ErrNotYourCell
+	}
+	last.Forfeit = &Move{X: x, Y: y}
+	return nil
+}
+
+// record appends a move to the log, current is the owner of the cell before the move is applied.
+func (h *moveHistory) record(player common.PlayerID, x, y int, current common.PlayerID) *MoveRecord {
+	rec := MoveRecord{
+		Player:   player,
+		X:        x,
+		Y:        y,
+		Previous: current,
+		Time:     time.Now(),
+	}
+
+	switch {
+	case h.deleted != nil && h.deleted.X == x && h.deleted.Y == y:
+		// The cell was emptied by a won challenge
+		rec.Conquest = true
+		rec.Won = true
+		rec.Previous = h.deletedP
+	case current != common.Empty:
+		// Playing on an opponent cell means the challenge was lost
+		rec.Conquest = true
+	}
+	h.deleted = nil
+
+	h.moves = append(h.moves, rec)
+	return &h.moves[len(h.moves)-1]
+}
+
+// pop removes the last move of the log.
+func (h *moveHistory) pop() (MoveRecord, error) {
+	if h.deleted != nil {
+		return MoveRecord{}, ErrConquestPending
+	}
+	if len(h.moves) == 0 {
+		return MoveRecord{}, ErrNothingToUndo
+	}
+
+	last := h.moves[len(h.moves)-1]
+	h.moves = h.moves[:len(h.moves)-1]
+	return last, nil
+}
+
+// clone returns an independent copy of the history.
+func (h *moveHistory) clone() moveHistory {
+	clone := *h
+	clone.moves = slices.Clone(h.moves)
+	if h.deleted != nil {
+		deleted := *h.deleted
+		clone.deleted = &deleted
+	}
+	return clone
+}
@@ -0,0 +1,121 @@
+package logic
+
+import (
+	"Goonker/common"
+	"testing"
+)
+
+func TestHistoryRecordsMoves(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+
+	// Won conquest of the O cell
+	game.DeleteMove(1, 1)
+	mustApply(t, game, common.P1, 1, 1)
+
+	// Lost conquest of the X cell
+	mustApply(t, game, common.P2, 0, 0)
+
+	history := game.History()
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 moves, got %d", len(history))
+	}
+
+	if history[0].Player != common.P1 || history[0].X != 0 || history[0].Y != 0 || history[0].Conquest {
+		t.Errorf("Unexpected first move %+v", history[0])
+	}
+	if !history[2].Conquest || !history[2].Won || history[2].Previous != common.P2 {
+		t.Errorf("Expected a won conquest of an O cell, got %+v", history[2])
+	}
+	if !history[3].Conquest || history[3].Won || history[3].Previous != common.P1 {
+		t.Errorf("Expected a lost conquest of an X cell, got %+v", history[3])
+	}
+	if history[0].Time.IsZero() {
+		t.Error("Expected moves to be timestamped")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	if err := game.Undo(); err != ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 1)
+	game.DeleteMove(1, 1)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 0, 0) // Lost conquest
+
+	// Undo the lost conquest, only the turn changes
+	if err := game.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if game.Turn != common.P2 || game.Board[0][0] != common.P1 {
+		t.Errorf("Unexpected state after undoing a lost conquest: turn=%d cell=%d", game.Turn, game.Board[0][0])
+	}
+
+	// Undo the won conquest, the cell goes back to O
+	if err := game.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if game.Board[1][1] != common.P2 || game.Turn != common.P1 || game.SymbolCount != 2 {
+		t.Errorf("Unexpected state after undoing a won conquest: cell=%d turn=%d count=%d", game.Board[1][1], game.Turn, game.SymbolCount)
+	}
+
+	// Undo a plain move
+	if err := game.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if game.Board[1][1] != common.Empty || game.SymbolCount != 1 || len(game.History()) != 1 {
+		t.Errorf("Unexpected state after undoing a move: cell=%d count=%d", game.Board[1][1], game.SymbolCount)
+	}
+}
+
+func TestUndoWinningMove(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 0)
+	mustApply(t, game, common.P1, 0, 1)
+	mustApply(t, game, common.P2, 1, 1)
+	mustApply(t, game, common.P1, 0, 2)
+
+	if err := game.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if game.GameOver || game.Winner != common.Empty || game.Turn != common.P1 {
+		t.Errorf("Expected the game to resume, got over=%v winner=%d turn=%d", game.GameOver, game.Winner, game.Turn)
+	}
+}
+
+func TestUndoPendingConquest(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	game.DeleteMove(0, 0)
+
+	if err := game.Undo(); err != ErrConquestPending {
+		t.Errorf("Expected ErrConquestPending, got %v", err)
+	}
+}
+
+func TestUltimateUndo(t *testing.T) {
+	game := NewUltimateLogic()
+	game.Board[0][1] = common.P2
+	game.Board[1][1] = common.P2
+	game.subCounts[0][0] = 2
+	game.ActiveX, game.ActiveY = 0, 0
+	game.Turn = common.P2
+
+	mustApply(t, game, common.P2, 2, 1) // Wins the sub-board
+	if err := game.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if game.SubBoards[0][0] != common.Empty || game.Board[2][1] != common.Empty {
+		t.Error("Expected the sub-board to be reopened")
+	}
+	if game.ActiveX != 0 || game.ActiveY != 0 || game.Turn != common.P2 || game.subCounts[0][0] != 2 {
+		t.Errorf("Unexpected state after undo: active=%d,%d turn=%d count=%d", game.ActiveX, game.ActiveY, game.Turn, game.subCounts[0][0])
+	}
+}
@@ -0,0 +1,290 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+
+	"Goonker/common"
+)
+
+// Q-learning constants
+const (
+	// Default training parameters
+	DefaultEpisodes                    = 100_000
+	DefaultLearningRate                = 0.2
+	DefaultDiscount                    = 0.95
+	DefaultTrainingExploration         = 0.2
+	DefaultTrainingConquestProbability = 0.5
+
+	// Episodes longer than maxEpisodeFactor times the number of cells are stopped,
+	// failed conquests can otherwise pass the turn forever
+	maxEpisodeFactor = 4
+
+	// Reward of the player whose move wins, draws are worth 0
+	rewardLearnedWin = 1.0
+
+	// Keys of conquest attempts are their cell followed by PolicyConquestSuffix
+	PolicyConquestSuffix = "*"
+)
+
+// LearnedTemperature is the sampling temperature of the learned bot of each difficulty.
+// Higher temperatures play weaker actions more often, 0 always plays the best one.
+var LearnedTemperature = map[string]float64{
+	common.DifficultyEasy:    1,
+	common.DifficultyMedium:  0.3,
+	common.DifficultyHard:    0.1,
+	common.DifficultyPerfect: 0,
+}
+
+// Error messages
+var (
+	ErrNoPolicy = errors.New("no learned policy loaded")
+)
+
+// TrainingOptions configure the self-play of a Policy.
+type TrainingOptions struct {
+	Episodes     int     // Games played
+	LearningRate float64 // Weight of a new estimate in the value of an action
+	Discount     float64 // Weight of the next position in the value of an action
+	Exploration  float64 // Probability of a random action instead of the best one
+
+	// Probability of winning the challenge of a conquest, conquests are not tried when 0
+	ConquestProbability float64
+}
+
+// DefaultTrainingOptions are the options of the train command.
+var DefaultTrainingOptions = TrainingOptions{
+	Episodes:            DefaultEpisodes,
+	LearningRate:        DefaultLearningRate,
+	Discount:            DefaultDiscount,
+	Exploration:         DefaultTrainingExploration,
+	ConquestProbability: DefaultTrainingConquestProbability,
+}
+
+// Policy holds the action values learned by self-play with tabular Q-learning.
+// Values are from the point of view of the player to move, between -1 (loss) and 1 (win).
+type Policy struct {
+	Mode                string  `json:"mode"`
+	Episodes            int     `json:"episodes"`             // Games played so far
+	ConquestProbability float64 `json:"conquest_probability"` // Conquests are part of the policy when > 0
+
+	// Values by position key, then by action key
+	Values map[string]map[string]float64 `json:"values"`
+}
+
+// NewPolicy creates an untrained policy for a game mode.
+func NewPolicy(mode string) (*Policy, error) {
+	if _, err := NewRules(mode); err != nil {
+		return nil, err
+	}
+	return &Policy{Mode: mode, Values: make(map[string]map[string]float64)}, nil
+}
+
+// LoadPolicy reads a policy file saved by Save.
+func LoadPolicy(path string) (*Policy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParsePolicy(file)
+}
+
+// ParsePolicy reads a policy encoded by Encode.
+func ParsePolicy(reader io.Reader) (*Policy, error) {
+	var policy Policy
+	if err := json.NewDecoder(reader).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+	if _, err := NewRules(policy.Mode); err != nil {
+		return nil, err
+	}
+	if policy.Values == nil {
+		policy.Values = make(map[string]map[string]float64)
+	}
+	return &policy, nil
+}
+
+// Save writes the policy to a file.
+func (p *Policy) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Encode writes the policy as JSON.
+func (p *Policy) Encode(w io.Writer) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Train plays games against itself and updates the values of the actions played.
+// Training continues from the current values, the conquest probability of the last training is kept.
+func (p *Policy) Train(options TrainingOptions, rng *rand.Rand) error {
+	p.ConquestProbability = options.ConquestProbability
+
+	for range options.Episodes {
+		game, err := NewRules(p.Mode)
+		if err != nil {
+			return err
+		}
+		p.playEpisode(game, options, rng)
+		p.Episodes++
+	}
+	return nil
+}
+
+// playEpisode plays one self-play game, updating the value of each action after it is played.
+func (p *Policy) playEpisode(game Rules, options TrainingOptions, rng *rand.Rand) {
+	state := game.State()
+	limit := maxEpisodeFactor * state.Width * state.Height
+
+	for range limit {
+		if _, over := game.Outcome(); over {
+			return
+		}
+
+		actions := Actions(game, p.conquests())
+		if len(actions) == 0 {
+			return
+		}
+
+		// Explore a random action, or play the best known one
+		position := policyKey(game)
+		action := actions[rng.IntN(len(actions))]
+		if rng.Float64() >= options.Exploration {
+			action = p.bestAction(position, actions)
+		}
+
+		player := game.CurrentTurn()
+		won := action.Conquest && rng.Float64() < options.ConquestProbability
+		if err := PlayAction(game, player, action, won); err != nil {
+			return
+		}
+
+		// The value of the next position is the one of the opponent, who moves next
+		target := 0.0
+		if winner, over := game.Outcome(); over {
+			if winner == player {
+				target = rewardLearnedWin
+			}
+		} else {
+			next := policyKey(game)
+			target = -options.Discount * p.value(next, p.bestAction(next, Actions(game, p.conquests())))
+		}
+
+		values, ok := p.Values[position]
+		if !ok {
+			values = make(map[string]float64)
+			p.Values[position] = values
+		}
+		key := actionKey(action)
+		values[key] += options.LearningRate * (target - values[key])
+	}
+}
+
+// conquests checks if conquest attempts are part of the policy.
+func (p *Policy) conquests() bool {
+	return p.ConquestProbability > 0
+}
+
+// value returns the value of an action in a position, 0 if it was never played.
+func (p *Policy) value(position string, action Action) float64 {
+	return p.Values[position][actionKey(action)]
+}
+
+// bestAction returns the action of highest value, the first one on ties.
+func (p *Policy) bestAction(position string, actions []Action) Action {
+	if len(actions) == 0 {
+		return Action{Move: Move{X: InvalidCoord, Y: InvalidCoord}}
+	}
+
+	best, bestValue := actions[0], math.Inf(-1)
+	for _, action := range actions {
+		if value := p.value(position, action); value > bestValue {
+			best, bestValue = action, value
+		}
+	}
+	return best
+}
+
+// policyKey identifies a position: the player to move, the board and the active sub-board if any.
+func policyKey(game Rules) string {
+	state := game.State()
+	key := playerSymbol(state.Turn) + " " + FormatBoard(state.Board)
+	if state.SubBoards != nil && state.ActiveX >= 0 {
+		key += " " + FormatCell(state.ActiveX, state.ActiveY)
+	}
+	return key
+}
+
+// actionKey identifies an action of a position.
+func actionKey(action Action) string {
+	key := FormatCell(action.X, action.Y)
+	if action.Conquest {
+		key += PolicyConquestSuffix
+	}
+	return key
+}
+
+// LearnedBot samples the actions of a learned policy, with probabilities growing with their value.
+// Positions the policy never reached are played by the fallback bot.
+type LearnedBot struct {
+	Temperature float64 // 0 plays the best action
+
+	policy   *Policy
+	fallback Bot
+	rng      *rand.Rand
+}
+
+// NewLearnedBot creates a bot playing a policy, drawing from rng.
+func NewLearnedBot(policy *Policy, temperature float64, fallback Bot, rng *rand.Rand) *LearnedBot {
+	return &LearnedBot{
+		Temperature: temperature,
+		policy:      policy,
+		fallback:    fallback,
+		rng:         rng,
+	}
+}
+
+// NextMove samples an action of the policy, or returns the move of the fallback bot.
+func (b *LearnedBot) NextMove(ctx context.Context, game Rules) Move {
+	position := policyKey(game)
+	if _, ok := b.policy.Values[position]; !ok {
+		return b.fallback.NextMove(ctx, game)
+	}
+
+	actions := Actions(game, b.policy.conquests())
+	if b.Temperature <= 0 || len(actions) == 0 {
+		return b.policy.bestAction(position, actions).Move
+	}
+
+	// Softmax of the values, shifted by the best one to avoid overflows
+	best := b.policy.value(position, b.policy.bestAction(position, actions))
+	weights := make([]float64, len(actions))
+	total := 0.0
+	for i, action := range actions {
+		weights[i] = math.Exp((b.policy.value(position, action) - best) / b.Temperature)
+		total += weights[i]
+	}
+
+	draw := b.rng.Float64() * total
+	for i, weight := range weights {
+		draw -= weight
+		if draw < 0 {
+			return actions[i].Move
+		}
+	}
+	return actions[len(actions)-1].Move
+}
@@ -0,0 +1,101 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"Goonker/common"
+)
+
+func TestPolicyTrainTakesWin(t *testing.T) {
+	policy, err := NewPolicy(common.ModeClassic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := DefaultTrainingOptions
+	options.Episodes = 20_000
+	if err := policy.Train(options, rand.New(rand.NewPCG(1, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Episodes != options.Episodes || len(policy.Values) == 0 {
+		t.Fatalf("Expected a trained policy, got %d episodes and %d positions", policy.Episodes, len(policy.Values))
+	}
+
+	/*
+	   X X .
+	   O O .
+	   . . .
+	   Turn: X
+	   Expected: 2,0 (win)
+	*/
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 0, 1)
+	mustApply(t, game, common.P1, 1, 0)
+	mustApply(t, game, common.P2, 1, 1)
+
+	bot := NewLearnedBot(policy, 0, fixedBot{Move{X: 2, Y: 2}}, rand.New(rand.NewPCG(1, 2)))
+	if move := bot.NextMove(context.Background(), game); move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected the winning move 2,0, got %v", move)
+	}
+}
+
+func TestPolicyEncodeRoundTrip(t *testing.T) {
+	policy, err := NewPolicy(common.ModeClassic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.Values["X ..."] = map[string]float64{"a1": 0.5, "b2" + PolicyConquestSuffix: -0.25}
+
+	var buffer bytes.Buffer
+	if err := policy.Encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePolicy(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Mode != policy.Mode || parsed.Values["X ..."]["a1"] != 0.5 {
+		t.Errorf("Expected the policy to round trip, got %+v", parsed)
+	}
+
+	if _, err := ParsePolicy(bytes.NewBufferString(`{"mode": "impossible"}`)); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("Expected ErrUnknownMode, got %v", err)
+	}
+}
+
+func TestLearnedBotSampling(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	policy, err := NewPolicy(common.ModeClassic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown positions are left to the fallback bot
+	fallback := fixedBot{Move{X: 2, Y: 2}}
+	if move := NewLearnedBot(policy, 0, fallback, rand.New(rand.NewPCG(1, 2))).NextMove(context.Background(), game); move != fallback.move {
+		t.Errorf("Expected the fallback move, got %v", move)
+	}
+
+	// The center is the best action, the corner a close second
+	policy.Values[policyKey(game)] = map[string]float64{
+		FormatCell(1, 1): 0.5,
+		FormatCell(0, 0): 0.4,
+	}
+
+	greedy := NewLearnedBot(policy, 0, fallback, rand.New(rand.NewPCG(1, 2)))
+	hot := NewLearnedBot(policy, 1, fallback, rand.New(rand.NewPCG(1, 2)))
+	played := make(map[Move]bool)
+	for range 100 {
+		if move := greedy.NextMove(context.Background(), game); move != (Move{X: 1, Y: 1}) {
+			t.Fatalf("Expected a greedy bot to play the center, got %v", move)
+		}
+		played[hot.NextMove(context.Background(), game)] = true
+	}
+	if len(played) < 3 {
+		t.Errorf("Expected a high temperature to sample several actions, got %v", played)
+	}
+}
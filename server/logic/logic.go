@@ -10,15 +10,12 @@ import (
 
 // Game constants
 const (
-	// Maximum number of moves in a Tic-Tac-Toe game
-	MaxMoves = common.BoardSize * common.BoardSize
-
 	// Display Symbols (for console debug)
 	SymbolP1    = "X"
 	SymbolP2    = "O"
 	SymbolEmpty = "."
 	SeparatorV  = " | "
-	SeparatorH  = "---------"
+	SeparatorH  = "-"
 )
 
 // Error messages
@@ -27,26 +24,160 @@ var (
 	ErrNotYourTurn  = errors.New("not your turn")
 	ErrOutOfBounds  = errors.New("out of bounds")
 	ErrCellOccupied = errors.New("cell already occupied")
+	ErrNotYourCell  = errors.New("cell not owned by the player")
+	ErrInvalidRules = errors.New("invalid board rules")
 )
 
+// BoardRules describes an m,n,k game: a Width x Height board
+// where WinLength aligned symbols are needed to win.
+type BoardRules struct {
+	Width     int `json:"width"`
+	Height    int `json:"height"`
+	WinLength int `json:"win_length"`
+}
+
+// ClassicRules are the rules of the standard 3x3 Tic-Tac-Toe.
+var ClassicRules = BoardRules{
+	Width:     common.BoardSize,
+	Height:    common.BoardSize,
+	WinLength: common.WinLength,
+}
+
+// Validate checks that the rules describe a playable board.
+func (r BoardRules) Validate() error {
+	if r.Width < 1 || r.Height < 1 || r.WinLength < 1 {
+		return ErrInvalidRules
+	}
+	if r.WinLength > max(r.Width, r.Height) {
+		return ErrInvalidRules
+	}
+	return nil
+}
+
+// directions are the four axes along which a line can be aligned.
+var directions = [4][2]int{
+	{1, 0},  // Horizontal
+	{0, 1},  // Vertical
+	{1, 1},  // Diagonal \
+	{1, -1}, // Diagonal /
+}
+
 // GameLogic manages the state of a Tic-Tac-Toe game
 type GameLogic struct {
-	Board       [common.BoardSize][common.BoardSize]common.PlayerID
+	Rules       BoardRules
+	Board       [][]common.PlayerID // Indexed as Board[x][y]
 	Turn        common.PlayerID
 	Winner      common.PlayerID
 	GameOver    bool
 	SymbolCount int
+
+	moveHistory
 }
 
-// NewGameLogic initializes a new game state.
-func NewGameLogic() *GameLogic {
+// NewGameLogic initializes a new game state for the given rules.
+func NewGameLogic(rules BoardRules) *GameLogic {
 	return &GameLogic{
-		Turn: common.P1, // X always starts
+		Rules: rules,
+		Board: newBoard(rules.Width, rules.Height),
+		Turn:  common.P1, // X always starts
+	}
+}
+
+// newBoard allocates an empty width x height board.
+func newBoard(width, height int) [][]common.PlayerID {
+	board := make([][]common.PlayerID, width)
+	for x := range board {
+		board[x] = make([]common.PlayerID, height)
+	}
+	return board
+}
+
+// cloneBoard returns a deep copy of a board.
+func cloneBoard(board [][]common.PlayerID) [][]common.PlayerID {
+	clone := make([][]common.PlayerID, len(board))
+	for x := range board {
+		clone[x] = append([]common.PlayerID(nil), board[x]...)
 	}
+	return clone
+}
+
+// Clone returns a deep copy of the game state.
+func (g *GameLogic) Clone() Rules {
+	clone := *g
+	clone.Board = cloneBoard(g.Board)
+	clone.moveHistory = g.moveHistory.clone()
+	return &clone
+}
+
+// CurrentTurn returns the player expected to move.
+func (g *GameLogic) CurrentTurn() common.PlayerID {
+	return g.Turn
+}
+
+// Outcome returns the winner (Empty for a draw) and whether the game is over.
+func (g *GameLogic) Outcome() (common.PlayerID, bool) {
+	return g.Winner, g.GameOver
+}
+
+// LegalMoves lists the empty cells of the board.
+func (g *GameLogic) LegalMoves() []Move {
+	if g.GameOver {
+		return nil
+	}
+
+	moves := make([]Move, 0, g.maxMoves()-g.SymbolCount)
+	for x := range g.Rules.Width {
+		for y := range g.Rules.Height {
+			if g.Board[x][y] == common.Empty {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+	}
+	return moves
+}
+
+// ConquestMoves lists the cells of the opponent of the current player.
+func (g *GameLogic) ConquestMoves() []Move {
+	if g.GameOver {
+		return nil
+	}
+
+	var moves []Move
+	for x := range g.Rules.Width {
+		for y := range g.Rules.Height {
+			if g.ShouldTriggerChallenge(g.Turn, x, y) {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+	}
+	return moves
+}
+
+// State serializes the game for the clients.
+func (g *GameLogic) State() common.UpdatePayload {
+	return common.UpdatePayload{
+		Board:  g.Board,
+		Width:  g.Rules.Width,
+		Height: g.Rules.Height,
+		Turn:   g.Turn,
+	}
+}
+
+// inBounds checks if the given cell lies on the board.
+func (g *GameLogic) inBounds(x, y int) bool {
+	return x >= 0 && x < g.Rules.Width && y >= 0 && y < g.Rules.Height
+}
+
+// maxMoves is the number of cells on the board.
+func (g *GameLogic) maxMoves() int {
+	return g.Rules.Width * g.Rules.Height
 }
 
 // ShouldTriggerChallenge checks if a challenge should be triggered
 func (g *GameLogic) ShouldTriggerChallenge(player common.PlayerID, x, y int) bool {
+	if !g.inBounds(x, y) {
+		return false
+	}
 	return g.Board[x][y] != player && g.Board[x][y] != common.Empty
 }
 
@@ -59,24 +190,26 @@ func (g *GameLogic) ApplyMove(player common.PlayerID, x, y int) error {
 	if player != g.Turn {
 		return ErrNotYourTurn
 	}
-	if x < 0 || x > common.BoardSize-1 || y < 0 || y > common.BoardSize-1 {
+	if !g.inBounds(x, y) {
 		return ErrOutOfBounds
 	}
 	if g.Board[x][y] == player {
 		return ErrCellOccupied
 	}
 
+	g.record(player, x, y, g.Board[x][y])
+
 	if g.Board[x][y] == common.Empty {
 		// Place the player symbol
 		g.Board[x][y] = player
 		g.SymbolCount++
 	}
 
-	// Check for win or draw
-	if g.checkWin(player) {
+	// Check for win or draw, only lines through the last move can have changed
+	if g.checkWinAt(x, y) {
 		g.Winner = player
 		g.GameOver = true
-	} else if g.SymbolCount >= MaxMoves {
+	} else if g.SymbolCount >= g.maxMoves() {
 		g.GameOver = true // Draw
 	} else {
 		// Toggle turn
@@ -92,54 +225,113 @@ func (g *GameLogic) ApplyMove(player common.PlayerID, x, y int) error {
 
 // DeleteMove empties the given board cell
 func (g *GameLogic) DeleteMove(x, y int) {
+	g.markDeleted(x, y, g.Board[x][y])
 	g.Board[x][y] = common.Empty
 	g.SymbolCount--
 }
 
-// checkWin scans rows, columns, and diagonals for a complete line.
-func (g *GameLogic) checkWin(p common.PlayerID) bool {
-	board := g.Board
-	boardSize := common.BoardSize
-
-	// Check Rows and Columns
-	for i := range boardSize {
-		rowWin, colWin := true, true
-		for j := range boardSize {
-			// Check Column i (varying rows j)
-			if board[i][j] != p {
-				colWin = false
-			}
-			// Check Row i (varying cols j)
-			if board[j][i] != p {
-				rowWin = false
-			}
-		}
-		if rowWin || colWin {
+// RestoreDeleted gives back the cell emptied by DeleteMove, when the conquering move is refused.
+func (g *GameLogic) RestoreDeleted() {
+	if cell, owner := g.takeDeleted(); cell != nil {
+		g.Board[cell.X][cell.Y] = owner
+		g.SymbolCount++
+	}
+}
+
+// ForfeitCell empties a cell of the player of the last move, as the penalty of a failed conquest.
+func (g *GameLogic) ForfeitCell(x, y int) error {
+	if g.GameOver {
+		return ErrGameOver
+	}
+	if !g.inBounds(x, y) {
+		return ErrOutOfBounds
+	}
+	if err := g.markForfeit(x, y, g.Board[x][y]); err != nil {
+		return err
+	}
+
+	g.Board[x][y] = common.Empty
+	g.SymbolCount--
+	return nil
+}
+
+// Undo reverts the last move, a won conquest gives the cell back to its previous owner.
+func (g *GameLogic) Undo() error {
+	last, err := g.pop()
+	if err != nil {
+		return err
+	}
+
+	// A cell lost as a penalty goes back to the player
+	if last.Forfeit != nil {
+		g.Board[last.Forfeit.X][last.Forfeit.Y] = last.Player
+		g.SymbolCount++
+	}
+
+	if g.Board[last.X][last.Y] != common.Empty && last.Previous == common.Empty {
+		g.SymbolCount--
+	}
+	g.Board[last.X][last.Y] = last.Previous
+
+	// The game could not be over before the move
+	g.Turn = last.Player
+	g.Winner = common.Empty
+	g.GameOver = false
+
+	return nil
+}
+
+// checkWinAt checks if the symbol at (x, y) is part of a winning line.
+func (g *GameLogic) checkWinAt(x, y int) bool {
+	p := g.Board[x][y]
+	if p == common.Empty {
+		return false
+	}
+
+	for _, dir := range directions {
+		// Count the aligned symbols on both sides of the cell
+		count := 1 + g.countDirection(p, x, y, dir[0], dir[1]) + g.countDirection(p, x, y, -dir[0], -dir[1])
+		if count >= g.Rules.WinLength {
 			return true
 		}
 	}
+	return false
+}
 
-	// Check Diagonals
-	diag1, diag2 := true, true
-	for i := range boardSize {
-		// Top-left to bottom-right (0,0 -> 1,1 -> 2,2)
-		if board[i][i] != p {
-			diag1 = false
-		}
-		// Bottom-left to top-right (0,2 -> 1,1 -> 2,0)
-		if board[i][boardSize-1-i] != p {
-			diag2 = false
-		}
+// countDirection counts the consecutive symbols of p starting next to (x, y) in the direction (dx, dy).
+func (g *GameLogic) countDirection(p common.PlayerID, x, y, dx, dy int) int {
+	count := 0
+	for cx, cy := x+dx, y+dy; g.inBounds(cx, cy) && g.Board[cx][cy] == p; cx, cy = cx+dx, cy+dy {
+		count++
 	}
+	return count
+}
 
-	return diag1 || diag2
+// checkWin scans the whole board for a line of WinLength symbols of p.
+func (g *GameLogic) checkWin(p common.PlayerID) bool {
+	for x := range g.Rules.Width {
+		for y := range g.Rules.Height {
+			if g.Board[x][y] != p {
+				continue
+			}
+
+			// Only look forward, backward lines are covered by earlier cells
+			for _, dir := range directions {
+				if 1+g.countDirection(p, x, y, dir[0], dir[1]) >= g.Rules.WinLength {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 // PrintConsoleBoard renders the board state to the console for debugging.
 func (g *GameLogic) PrintConsoleBoard() {
-	for y := range common.BoardSize {
+	separator := strings.Repeat(SeparatorH, g.Rules.Width*len(SeparatorV))
+	for y := range g.Rules.Height {
 		var line []string
-		for x := range common.BoardSize {
+		for x := range g.Rules.Width {
 			cell := g.Board[x][y]
 			switch cell {
 			case common.P1:
@@ -153,8 +345,8 @@ func (g *GameLogic) PrintConsoleBoard() {
 		fmt.Println(strings.Join(line, SeparatorV))
 
 		// Print horizontal separator only between rows
-		if y < common.BoardSize-1 {
-			fmt.Println(SeparatorH)
+		if y < g.Rules.Height-1 {
+			fmt.Println(separator)
 		}
 	}
 }
@@ -6,7 +6,7 @@ import (
 )
 
 func TestNewGameLogic(t *testing.T) {
-	game := NewGameLogic()
+	game := NewGameLogic(ClassicRules)
 	if game.Turn != common.P1 {
 		t.Errorf("Expected Turn to be P1, got %d", game.Turn)
 	}
@@ -19,7 +19,7 @@ func TestNewGameLogic(t *testing.T) {
 }
 
 func TestApplyMove(t *testing.T) {
-	game := NewGameLogic()
+	game := NewGameLogic(ClassicRules)
 
 	// Valid move
 	err := game.ApplyMove(common.P1, 0, 0)
@@ -46,7 +46,9 @@ func TestApplyMove(t *testing.T) {
 	}
 }
 
-func mustMove(t *testing.T, game *GameLogic, p common.PlayerID, x, y int) {
+// mustApply plays a move on any variant, failing the test on error.
+func mustApply(t *testing.T, game Rules, p common.PlayerID, x, y int) {
+	t.Helper()
 	if err := game.ApplyMove(p, x, y); err != nil {
 		t.Fatalf("ApplyMove(%v, %d, %d) failed: %v", p, x, y, err)
 	}
@@ -54,12 +56,12 @@ func mustMove(t *testing.T, game *GameLogic, p common.PlayerID, x, y int) {
 
 func TestCheckWin(t *testing.T) {
 	// Row win
-	game := NewGameLogic()
-	mustMove(t, game, common.P1, 0, 0) // X
-	mustMove(t, game, common.P2, 1, 0) // O
-	mustMove(t, game, common.P1, 0, 1) // X
-	mustMove(t, game, common.P2, 1, 1) // O
-	mustMove(t, game, common.P1, 0, 2) // X wins
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0) // X
+	mustApply(t, game, common.P2, 1, 0) // O
+	mustApply(t, game, common.P1, 0, 1) // X
+	mustApply(t, game, common.P2, 1, 1) // O
+	mustApply(t, game, common.P1, 0, 2) // X wins
 
 	if !game.GameOver {
 		t.Error("Expected game over")
@@ -69,12 +71,12 @@ func TestCheckWin(t *testing.T) {
 	}
 
 	// Diagonal win
-	game = NewGameLogic()
-	mustMove(t, game, common.P1, 0, 0)
-	mustMove(t, game, common.P2, 0, 1)
-	mustMove(t, game, common.P1, 1, 1)
-	mustMove(t, game, common.P2, 0, 2)
-	mustMove(t, game, common.P1, 2, 2)
+	game = NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 0, 1)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 0, 2)
+	mustApply(t, game, common.P1, 2, 2)
 
 	if !game.GameOver {
 		t.Error("Expected game over")
@@ -86,7 +88,7 @@ func TestCheckWin(t *testing.T) {
 
 func TestDraw(t *testing.T) {
 	// Fill board without win
-	game := NewGameLogic()
+	game := NewGameLogic(ClassicRules)
 
 	/*
 	   X O X
@@ -130,15 +132,15 @@ func TestDraw(t *testing.T) {
 	// 8. O -> 2,0
 	// 9. X -> 2,2
 
-	mustMove(t, game, common.P1, 0, 0)
-	mustMove(t, game, common.P2, 0, 1)
-	mustMove(t, game, common.P1, 0, 2)
-	mustMove(t, game, common.P2, 1, 1)
-	mustMove(t, game, common.P1, 1, 0)
-	mustMove(t, game, common.P2, 1, 2)
-	mustMove(t, game, common.P1, 2, 1)
-	mustMove(t, game, common.P2, 2, 0)
-	mustMove(t, game, common.P1, 2, 2)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 0, 1)
+	mustApply(t, game, common.P1, 0, 2)
+	mustApply(t, game, common.P2, 1, 1)
+	mustApply(t, game, common.P1, 1, 0)
+	mustApply(t, game, common.P2, 1, 2)
+	mustApply(t, game, common.P1, 2, 1)
+	mustApply(t, game, common.P2, 2, 0)
+	mustApply(t, game, common.P1, 2, 2)
 
 	if !game.GameOver {
 		t.Error("Expected game over (draw)")
@@ -149,7 +151,7 @@ func TestDraw(t *testing.T) {
 }
 
 func TestShouldTriggerChallenge(t *testing.T) {
-	game := NewGameLogic()
+	game := NewGameLogic(ClassicRules)
 	game.Board[0][0] = common.P2
 
 	// P1 wants to take P2's cell
@@ -162,3 +164,65 @@ func TestShouldTriggerChallenge(t *testing.T) {
 		t.Error("Expected no trigger challenge for empty cell")
 	}
 }
+
+func TestBoardRulesValidate(t *testing.T) {
+	if err := ClassicRules.Validate(); err != nil {
+		t.Errorf("Expected classic rules to be valid, got %v", err)
+	}
+
+	invalid := []BoardRules{
+		{Width: 0, Height: 3, WinLength: 3},
+		{Width: 3, Height: 3, WinLength: 0},
+		{Width: 3, Height: 3, WinLength: 4},
+	}
+	for _, rules := range invalid {
+		if err := rules.Validate(); err != ErrInvalidRules {
+			t.Errorf("Expected ErrInvalidRules for %+v, got %v", rules, err)
+		}
+	}
+}
+
+func TestLargerBoard(t *testing.T) {
+	// 5x4 board, 4 in a row
+	game := NewGameLogic(BoardRules{Width: 5, Height: 4, WinLength: 4})
+	if len(game.Board) != 5 || len(game.Board[0]) != 4 {
+		t.Fatalf("Expected 5x4 board, got %dx%d", len(game.Board), len(game.Board[0]))
+	}
+
+	// Three in a row is not enough
+	mustApply(t, game, common.P1, 1, 3)
+	mustApply(t, game, common.P2, 0, 0)
+	mustApply(t, game, common.P1, 2, 2)
+	mustApply(t, game, common.P2, 1, 0)
+	mustApply(t, game, common.P1, 3, 1)
+	mustApply(t, game, common.P2, 2, 0)
+	if game.GameOver {
+		t.Fatal("Expected game to continue with three aligned symbols")
+	}
+
+	// Fourth symbol on the anti-diagonal wins
+	mustApply(t, game, common.P1, 4, 0)
+	if !game.GameOver || game.Winner != common.P1 {
+		t.Errorf("Expected P1 to win on the anti-diagonal, got over=%v winner=%d", game.GameOver, game.Winner)
+	}
+
+	// Out of bounds on the wider axis
+	game = NewGameLogic(BoardRules{Width: 5, Height: 4, WinLength: 4})
+	if err := game.ApplyMove(common.P1, 4, 4); err != ErrOutOfBounds {
+		t.Errorf("Expected ErrOutOfBounds, got %v", err)
+	}
+}
+
+func TestCheckWinFullScan(t *testing.T) {
+	game := NewGameLogic(BoardRules{Width: 4, Height: 4, WinLength: 3})
+	game.Board[1][1] = common.P2
+	game.Board[2][2] = common.P2
+	game.Board[3][3] = common.P2
+
+	if !game.checkWin(common.P2) {
+		t.Error("Expected diagonal win for P2")
+	}
+	if game.checkWin(common.P1) {
+		t.Error("Expected no win for P1")
+	}
+}
@@ -0,0 +1,219 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"Goonker/common"
+)
+
+// MCTS constants
+const (
+	// UCT exploration constant used when none is configured
+	DefaultExploration = math.Sqrt2
+
+	// Playouts run when neither an iteration nor a time budget is configured
+	DefaultIterations = 1000
+
+	// Playouts longer than maxPlayoutFactor times the number of cells count as a draw,
+	// failed conquests can otherwise pass the turn forever
+	maxPlayoutFactor = 4
+
+	// Rewards of a playout
+	rewardWin  = 1.0
+	rewardDraw = 0.5
+)
+
+// MCTSOptions configure a Monte Carlo Tree Search bot.
+type MCTSOptions struct {
+	Iterations  int           // Playouts run at most, 0 for no limit
+	TimeBudget  time.Duration // Time after which no playout is started, 0 for no limit
+	Exploration float64       // UCT exploration constant, DefaultExploration when 0
+
+	// Probability of winning the challenge of a conquest, conquests are not tried when 0
+	ConquestProbability float64
+}
+
+// MCTS is a bot playing the most visited move of a Monte Carlo Tree Search.
+// Conquest attempts are chance nodes, won with the configured probability.
+type MCTS struct {
+	options MCTSOptions
+	rng     *rand.Rand
+}
+
+// mctsNode is a node of the search tree.
+type mctsNode struct {
+	parent   *mctsNode
+	action   Action          // Action leading to the node
+	player   common.PlayerID // Player who chose the action
+	children []*mctsNode     // Tried actions, or the won and lost outcomes of a chance node
+	untried  []Action
+	expanded bool // untried has been filled
+	chance   bool // The node is a conquest attempt waiting for its outcome
+	visits   int
+	reward   float64 // Sum of the rewards of player
+}
+
+// Outcomes of a chance node
+const (
+	outcomeWon = iota
+	outcomeLost
+)
+
+// NewMCTS creates a MCTS bot drawing from rng.
+func NewMCTS(options MCTSOptions, rng *rand.Rand) *MCTS {
+	if options.Exploration == 0 {
+		options.Exploration = DefaultExploration
+	}
+	if options.Iterations == 0 && options.TimeBudget == 0 {
+		options.Iterations = DefaultIterations
+	}
+	return &MCTS{options: options, rng: rng}
+}
+
+// NextMove returns the cell of the best action found by the search.
+func (m *MCTS) NextMove(ctx context.Context, game Rules) Move {
+	return m.NextAction(ctx, game).Move
+}
+
+// NextAction searches game, which is left untouched, and returns the most visited action.
+// The search stops early when ctx is done.
+func (m *MCTS) NextAction(ctx context.Context, game Rules) Action {
+	root := &mctsNode{player: Opponent(game.CurrentTurn())}
+	deadline := searchDeadline(ctx, m.options.TimeBudget)
+
+	for i := 0; m.options.Iterations == 0 || i < m.options.Iterations; i++ {
+		// At least one playout, so that a move is found
+		if i > 0 && (ctx.Err() != nil || !deadline.IsZero() && time.Now().After(deadline)) {
+			break
+		}
+		m.iterate(root, game.Clone())
+	}
+
+	best := Action{Move: Move{X: InvalidCoord, Y: InvalidCoord}}
+	bestVisits := 0
+	for _, child := range root.children {
+		if child.visits > bestVisits {
+			best, bestVisits = child.action, child.visits
+		}
+	}
+	return best
+}
+
+// iterate runs one selection, expansion, playout and backpropagation on game, a copy of the root position.
+func (m *MCTS) iterate(root *mctsNode, game Rules) {
+	node := root
+
+	// Selection: descend through fully expanded nodes
+	for {
+		if node.chance {
+			node = m.resolve(node, game)
+			continue
+		}
+		m.expand(node, game)
+		if len(node.untried) > 0 || len(node.children) == 0 {
+			break
+		}
+		node = m.selectChild(node)
+		if !node.chance {
+			PlayAction(game, node.player, node.action, false)
+		}
+	}
+
+	// Expansion: try one new action
+	if len(node.untried) > 0 {
+		i := m.rng.IntN(len(node.untried))
+		action := node.untried[i]
+		node.untried[i] = node.untried[len(node.untried)-1]
+		node.untried = node.untried[:len(node.untried)-1]
+
+		child := &mctsNode{parent: node, action: action, player: game.CurrentTurn()}
+		node.children = append(node.children, child)
+
+		if action.Conquest {
+			child.chance = true
+			child.children = []*mctsNode{
+				outcomeWon:  {parent: child, action: action, player: child.player},
+				outcomeLost: {parent: child, action: action, player: child.player},
+			}
+			node = m.resolve(child, game)
+		} else {
+			PlayAction(game, child.player, action, false)
+			node = child
+		}
+	}
+
+	// Simulation and backpropagation
+	winner := m.playout(game)
+	for ; node != nil; node = node.parent {
+		node.visits++
+		switch winner {
+		case common.Empty:
+			node.reward += rewardDraw
+		case node.player:
+			node.reward += rewardWin
+		}
+	}
+}
+
+// expand lists the actions of a node the first time it is reached.
+func (m *MCTS) expand(node *mctsNode, game Rules) {
+	if !node.expanded {
+		node.untried = Actions(game, m.options.ConquestProbability > 0)
+		node.expanded = true
+	}
+}
+
+// resolve draws the outcome of a chance node, plays it and returns the outcome node.
+func (m *MCTS) resolve(node *mctsNode, game Rules) *mctsNode {
+	won := m.rng.Float64() < m.options.ConquestProbability
+	PlayAction(game, node.player, node.action, won)
+	if won {
+		return node.children[outcomeWon]
+	}
+	return node.children[outcomeLost]
+}
+
+// selectChild returns the child with the best UCT value.
+func (m *MCTS) selectChild(node *mctsNode) *mctsNode {
+	logVisits := math.Log(float64(node.visits))
+
+	var best *mctsNode
+	bestValue := math.Inf(-1)
+	for _, child := range node.children {
+		visits := float64(child.visits)
+		value := child.reward/visits + m.options.Exploration*math.Sqrt(logVisits/visits)
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// playout plays random actions until the game ends and returns the winner (Empty for a draw).
+func (m *MCTS) playout(game Rules) common.PlayerID {
+	state := game.State()
+	limit := maxPlayoutFactor * state.Width * state.Height
+
+	for range limit {
+		if winner, over := game.Outcome(); over {
+			return winner
+		}
+
+		actions := Actions(game, m.options.ConquestProbability > 0)
+		if len(actions) == 0 {
+			return common.Empty
+		}
+
+		action := actions[m.rng.IntN(len(actions))]
+		won := action.Conquest && m.rng.Float64() < m.options.ConquestProbability
+		PlayAction(game, game.CurrentTurn(), action, won)
+	}
+
+	if winner, over := game.Outcome(); over {
+		return winner
+	}
+	return common.Empty
+}
@@ -0,0 +1,188 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"Goonker/common"
+)
+
+// newTestMCTS creates a seeded MCTS bot, so that tests are reproducible.
+func newTestMCTS(options MCTSOptions) *MCTS {
+	return NewMCTS(options, rand.New(rand.NewPCG(1, 2)))
+}
+
+func TestMCTSTakesWin(t *testing.T) {
+	/*
+	   O O .
+	   . X .
+	   X . .
+	   Turn: O
+	   Expected: 0,2 (win)
+	*/
+	game := NewGameLogic(ClassicRules)
+	game.Board[0][0] = common.P2
+	game.Board[0][1] = common.P2
+	game.Board[1][1] = common.P1
+	game.Board[2][0] = common.P1
+	game.SymbolCount = 4
+	game.Turn = common.P2
+
+	move := newTestMCTS(MCTSOptions{Iterations: 2000}).NextMove(context.Background(), game)
+	if move != (Move{X: 0, Y: 2}) {
+		t.Errorf("Expected winning move at 0,2, got %v", move)
+	}
+}
+
+func TestMCTSBlocks(t *testing.T) {
+	/*
+	   X X .
+	   . O .
+	   . . .
+	   Turn: O
+	   Expected: 2,0 (block X)
+	*/
+	game := NewGameLogic(ClassicRules)
+	game.Board[0][0] = common.P1
+	game.Board[1][0] = common.P1
+	game.Board[1][1] = common.P2
+	game.SymbolCount = 3
+	game.Turn = common.P2
+
+	move := newTestMCTS(MCTSOptions{Iterations: 5000}).NextMove(context.Background(), game)
+	if move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected defensive move at 2,0, got %v", move)
+	}
+}
+
+// conquestPosition returns a game where X can only win at once by conquering 2,0:
+//
+//	X X O
+//	. O .
+//	. . .
+func conquestPosition() *GameLogic {
+	game := NewGameLogic(ClassicRules)
+	game.Board[0][0] = common.P1
+	game.Board[1][0] = common.P1
+	game.Board[2][0] = common.P2
+	game.Board[1][1] = common.P2
+	game.SymbolCount = 4
+	return game
+}
+
+func TestMCTSConquest(t *testing.T) {
+	// A sure conquest wins the game
+	action := newTestMCTS(MCTSOptions{Iterations: 2000, ConquestProbability: 1}).NextAction(context.Background(), conquestPosition())
+	if !action.Conquest || action.Move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected a conquest of 2,0, got %+v", action)
+	}
+
+	// Without conquests, X must block the diagonal of O
+	action = newTestMCTS(MCTSOptions{Iterations: 2000}).NextAction(context.Background(), conquestPosition())
+	if action.Conquest || action.Move != (Move{X: 0, Y: 2}) {
+		t.Errorf("Expected a block at 0,2, got %+v", action)
+	}
+}
+
+func TestMCTSTimeBudget(t *testing.T) {
+	budget := 50 * time.Millisecond
+	game := NewUltimateLogic()
+
+	start := time.Now()
+	move := newTestMCTS(MCTSOptions{TimeBudget: budget}).NextMove(context.Background(), game)
+	if elapsed := time.Since(start); elapsed > 10*budget {
+		t.Errorf("Expected the search to respect its budget, took %v", elapsed)
+	}
+	if err := game.ApplyMove(common.P1, move.X, move.Y); err != nil {
+		t.Errorf("Expected a legal move, got %v: %v", move, err)
+	}
+}
+
+func TestMCTSCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	game := NewUltimateLogic()
+
+	// A single playout is run, so that the move is legal
+	move := newTestMCTS(MCTSOptions{}).NextMove(ctx, game)
+	if err := game.ApplyMove(common.P1, move.X, move.Y); err != nil {
+		t.Errorf("Expected a legal move, got %v: %v", move, err)
+	}
+}
+
+func TestMCTSNoLegalMove(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	game.GameOver = true
+
+	move := newTestMCTS(MCTSOptions{}).NextMove(context.Background(), game)
+	if move.X != InvalidCoord || move.Y != InvalidCoord {
+		t.Errorf("Expected no move, got %v", move)
+	}
+}
+
+func TestPlayActionConquest(t *testing.T) {
+	conquest := Action{Move: Move{X: 2, Y: 0}, Conquest: true}
+
+	// Won conquest: the cell changes hands and X wins the row
+	game := conquestPosition()
+	if err := PlayAction(game, common.P1, conquest, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if winner, over := game.Outcome(); !over || winner != common.P1 {
+		t.Error("Expected X to win with the conquest")
+	}
+
+	// Lost conquest: the cell is kept and the turn passes
+	game = conquestPosition()
+	if err := PlayAction(game, common.P1, conquest, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if game.Board[2][0] != common.P2 || game.Turn != common.P2 {
+		t.Error("Expected a lost conquest to only pass the turn")
+	}
+
+	// Both are recorded as conquests and can be undone
+	history := game.History()
+	if len(history) != 1 || !history[0].Conquest || history[0].Won {
+		t.Errorf("Expected a lost conquest in the history, got %+v", history)
+	}
+}
+
+func TestPlayActionRefusedConquest(t *testing.T) {
+	conquest := Action{Move: Move{X: 2, Y: 0}, Conquest: true}
+
+	// A won conquest out of turn, or of an own cell, leaves the game untouched
+	for _, tc := range []struct {
+		player common.PlayerID
+		action Action
+		err    error
+	}{
+		{common.P2, conquest, ErrNotYourTurn},
+		{common.P1, Action{Move: Move{X: 0, Y: 0}, Conquest: true}, ErrNotAConquest},
+	} {
+		game := conquestPosition()
+		board := cloneBoard(game.Board)
+		if err := PlayAction(game, tc.player, tc.action, true); !errors.Is(err, tc.err) {
+			t.Errorf("Expected %v, got %v", tc.err, err)
+		}
+		if !slices.EqualFunc(game.Board, board, slices.Equal) || len(game.History()) != 0 {
+			t.Errorf("Expected the refused conquest to leave the board untouched, got %v", game.Board)
+		}
+	}
+
+	// A cell emptied for a refused move is given back, and undo still works
+	game := conquestPosition()
+	mustApply(t, game, common.P1, 1, 2)
+	game.DeleteMove(2, 0)
+	game.RestoreDeleted()
+	if game.Board[2][0] != common.P2 {
+		t.Error("Expected the deleted cell to be given back")
+	}
+	if err := game.Undo(); err != nil {
+		t.Errorf("Expected undo to work after a refused conquest, got %v", err)
+	}
+}
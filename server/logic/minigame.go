@@ -0,0 +1,170 @@
+package logic
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"Goonker/common"
+)
+
+// Minigame timing constants
+const (
+	// Accepted error of the round trip time removed from the time measured by the server
+	MinigameLatencySlack = 50
+
+	// Step of the bar positions checked within the slack
+	barSlackStep = 10
+
+	// Reactions faster than this are anticipations, not reactions
+	MinReactionTime = 100
+
+	// Memory grid, 3x3 like the board
+	memoryCells = 9
+)
+
+// minigameLevel holds the parameters of the minigames at one difficulty.
+type minigameLevel struct {
+	reactionLimit  int     // Milliseconds after the signal to click
+	sequenceLength int     // Cells to repeat
+	sequenceStep   int     // Milliseconds each cell is lit
+	barPeriod      int     // Milliseconds for the bar to go and come back
+	barWidth       float64 // Width of the target zone
+}
+
+// minigameLevels lists the minigame parameters, by difficulty.
+var minigameLevels = map[int]minigameLevel{
+	common.ChallengeEasy:   {reactionLimit: 600, sequenceLength: 4, sequenceStep: 700, barPeriod: 2000, barWidth: 0.25},
+	common.ChallengeMedium: {reactionLimit: 450, sequenceLength: 5, sequenceStep: 600, barPeriod: 1500, barWidth: 0.18},
+	common.ChallengeHard:   {reactionLimit: 350, sequenceLength: 6, sequenceStep: 500, barPeriod: 1000, barWidth: 0.12},
+}
+
+// minigameQuestions tells how to play each minigame.
+var minigameQuestions = map[string]string{
+	common.MinigameReaction: "Click as soon as the screen turns green!",
+	common.MinigameMemory:   "Repeat the sequence of cells!",
+	common.MinigameBar:      "Stop the bar in the target zone!",
+}
+
+// Range of the reaction delay before the signal, in milliseconds
+const (
+	minReactionDelay = 1500
+	maxReactionDelay = 4000
+)
+
+// MinigameGenerator is a challenge source creating minigames with random parameters.
+// Minigames have no category, they are only played in rooms allowing every category.
+type MinigameGenerator struct {
+	rng           *rand.Rand
+	minDifficulty int
+	maxDifficulty int
+	enabled       bool
+}
+
+// NewMinigameGenerator creates a generator of every minigame and difficulty.
+func NewMinigameGenerator(rng *rand.Rand) *MinigameGenerator {
+	generator := &MinigameGenerator{rng: rng}
+	_ = generator.SetFilter(common.ChallengeFilter{})
+	return generator
+}
+
+// SetFilter restricts the difficulty of the minigames, it fails if filter restricts the categories.
+func (g *MinigameGenerator) SetFilter(filter common.ChallengeFilter) error {
+	if err := ValidateChallengeFilter(filter); err != nil {
+		return err
+	}
+
+	minDifficulty := max(filter.MinDifficulty, common.ChallengeEasy)
+	maxDifficulty := common.ChallengeHard
+	if filter.MaxDifficulty > 0 {
+		maxDifficulty = min(filter.MaxDifficulty, common.ChallengeHard)
+	}
+	if len(filter.Categories) > 0 || minDifficulty > maxDifficulty {
+		return ErrNoChallenges
+	}
+
+	g.minDifficulty, g.maxDifficulty = minDifficulty, maxDifficulty
+	g.enabled = true
+	return nil
+}
+
+// PickChallenge draws a minigame and its parameters.
+func (g *MinigameGenerator) PickChallenge() (*Challenge, error) {
+	if !g.enabled {
+		return nil, ErrNoChallenges
+	}
+
+	difficulty := g.minDifficulty + g.rng.IntN(g.maxDifficulty-g.minDifficulty+1)
+	minigame := NewMinigame(g.rng, common.Minigames[g.rng.IntN(len(common.Minigames))], difficulty)
+	return &Challenge{
+		Type:       common.ChallengeMinigame,
+		Question:   minigameQuestions[minigame.Kind],
+		Difficulty: difficulty,
+		Language:   DefaultChallengeLanguage,
+		Minigame:   &minigame,
+	}, nil
+}
+
+// NewMinigame draws the parameters of a minigame of a kind and a difficulty.
+func NewMinigame(rng *rand.Rand, kind string, difficulty int) common.MinigamePayload {
+	level := minigameLevels[difficulty]
+	minigame := common.MinigamePayload{Kind: kind}
+
+	switch kind {
+	case common.MinigameReaction:
+		minigame.Delay = minReactionDelay + rng.IntN(maxReactionDelay-minReactionDelay)
+		minigame.Limit = level.reactionLimit
+	case common.MinigameMemory:
+		// Consecutive cells differ, so that every step is visible
+		for len(minigame.Sequence) < level.sequenceLength {
+			cell := rng.IntN(memoryCells)
+			if n := len(minigame.Sequence); n == 0 || minigame.Sequence[n-1] != cell {
+				minigame.Sequence = append(minigame.Sequence, cell)
+			}
+		}
+		minigame.Step = level.sequenceStep
+	case common.MinigameBar:
+		minigame.Period = level.barPeriod
+		minigame.Phase = rng.Float64()
+		minigame.Width = level.barWidth
+		minigame.Target = rng.Float64() * (1 - level.barWidth)
+	}
+	return minigame
+}
+
+// GradeMinigame checks the events of a minigame, the last one played elapsed after the minigame was shown.
+// elapsed is measured by the server, the times reported by the client are ignored so that it can not claim a perfect timing.
+func GradeMinigame(minigame common.MinigamePayload, events []common.MinigameEvent, elapsed time.Duration) bool {
+	at := int(elapsed.Milliseconds())
+	if len(events) == 0 || at < 0 {
+		return false
+	}
+
+	switch minigame.Kind {
+	case common.MinigameReaction:
+		reaction := at - minigame.Delay
+		return len(events) == 1 && reaction >= MinReactionTime && reaction <= minigame.Limit+MinigameLatencySlack
+	case common.MinigameMemory:
+		if len(events) != len(minigame.Sequence) || at < len(minigame.Sequence)*minigame.Step {
+			return false
+		}
+		for i, event := range events {
+			if event.Cell != minigame.Sequence[i] {
+				return false
+			}
+		}
+		return true
+	case common.MinigameBar:
+		return len(events) == 1 && barHits(minigame, at)
+	}
+	return false
+}
+
+// barHits checks if the bar of a bar minigame is in the target zone at a time, give or take MinigameLatencySlack.
+func barHits(minigame common.MinigamePayload, at int) bool {
+	for t := at - MinigameLatencySlack; t <= at+MinigameLatencySlack; t += barSlackStep {
+		if position := minigame.BarPosition(t); position >= minigame.Target && position <= minigame.Target+minigame.Width {
+			return true
+		}
+	}
+	return false
+}
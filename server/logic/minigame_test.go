@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"Goonker/common"
+)
+
+func TestNewMinigame(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for difficulty := common.ChallengeEasy; difficulty <= common.ChallengeHard; difficulty++ {
+		reaction := NewMinigame(rng, common.MinigameReaction, difficulty)
+		if reaction.Delay < minReactionDelay || reaction.Delay >= maxReactionDelay || reaction.Limit == 0 {
+			t.Errorf("Unexpected reaction parameters %+v", reaction)
+		}
+
+		memory := NewMinigame(rng, common.MinigameMemory, difficulty)
+		if len(memory.Sequence) != minigameLevels[difficulty].sequenceLength {
+			t.Errorf("Expected %d cells, got %v", minigameLevels[difficulty].sequenceLength, memory.Sequence)
+		}
+		for i, cell := range memory.Sequence {
+			if cell < 0 || cell >= memoryCells || (i > 0 && cell == memory.Sequence[i-1]) {
+				t.Errorf("Unexpected memory sequence %v", memory.Sequence)
+			}
+		}
+
+		bar := NewMinigame(rng, common.MinigameBar, difficulty)
+		if bar.Target < 0 || bar.Target+bar.Width > 1 || bar.Period == 0 {
+			t.Errorf("Unexpected bar parameters %+v", bar)
+		}
+	}
+}
+
+func TestGradeMinigame(t *testing.T) {
+	reaction := common.MinigamePayload{Kind: common.MinigameReaction, Delay: 2000, Limit: 400}
+	memory := common.MinigamePayload{Kind: common.MinigameMemory, Sequence: []int{4, 0, 8}, Step: 500}
+	bar := common.MinigamePayload{Kind: common.MinigameBar, Period: 1000, Target: 0.4, Width: 0.2}
+
+	click := []common.MinigameEvent{{}}
+	memoryEvents := []common.MinigameEvent{{Cell: 4}, {Cell: 0}, {Cell: 8}}
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	testCases := []struct {
+		name     string
+		minigame common.MinigamePayload
+		events   []common.MinigameEvent
+		elapsed  time.Duration
+		win      bool
+	}{
+		{"reaction", reaction, click, ms(2250), true},
+		{"reaction within the latency slack", reaction, click, ms(2430), true},
+		{"reaction too slow", reaction, click, ms(2500), false},
+		{"reaction false start", reaction, click, ms(1900), false},
+		{"reaction anticipated", reaction, click, ms(2050), false},
+		{"reaction time claimed by the client", reaction, []common.MinigameEvent{{At: 2250}}, ms(3000), false},
+		{"no event", reaction, nil, ms(2250), false},
+		{"memory", memory, memoryEvents, ms(2600), true},
+		{"memory wrong cell", memory, []common.MinigameEvent{{Cell: 4}, {Cell: 1}, {Cell: 8}}, ms(2600), false},
+		{"memory before the end of the sequence", memory, memoryEvents, ms(1400), false},
+		{"memory incomplete", memory, memoryEvents[:2], ms(2600), false},
+		{"bar in the zone", bar, click, ms(250), true},              // Position 0.5 going up
+		{"bar coming back", bar, click, ms(2750), true},             // Position 0.5 going down
+		{"bar within the latency slack", bar, click, ms(160), true}, // Position 0.32, 0.42 50ms later
+		{"bar outside the zone", bar, click, ms(100), false},        // Position 0.2
+		{"bar time claimed by the client", bar, []common.MinigameEvent{{At: 250}}, ms(100), false},
+		{"bar stopped twice", bar, []common.MinigameEvent{{}, {}}, ms(250), false},
+	}
+	for _, tc := range testCases {
+		if win := GradeMinigame(tc.minigame, tc.events, tc.elapsed); win != tc.win {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.win, win)
+		}
+	}
+}
+
+func TestMinigameGenerator(t *testing.T) {
+	generator := NewMinigameGenerator(rand.New(rand.NewPCG(1, 2)))
+	if err := generator.SetFilter(common.ChallengeFilter{MinDifficulty: common.ChallengeHard}); err != nil {
+		t.Fatalf("Expected a valid filter, got %v", err)
+	}
+	for range 10 {
+		challenge, err := generator.PickChallenge()
+		if err != nil || challenge.Type != common.ChallengeMinigame || challenge.Minigame == nil || challenge.Difficulty != common.ChallengeHard {
+			t.Fatalf("Expected a hard minigame, got %+v (%v)", challenge, err)
+		}
+		if payload := challenge.Payload(); payload.Minigame == nil || payload.Question == "" {
+			t.Fatalf("Expected the minigame and its instructions to be sent, got %+v", payload)
+		}
+	}
+
+	if err := generator.SetFilter(common.ChallengeFilter{Categories: []string{common.CategoryMath}}); !errors.Is(err, ErrNoChallenges) {
+		t.Errorf("Expected no minigame in math rooms, got %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+package logic
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"Goonker/common"
+)
+
+// Game record format
+//
+// A record starts with headers, one per line, followed by a blank line and the moves:
+//
+//	[Mode "classic"]
+//	[PlayerX "Player 1"]
+//	[PlayerO "Bot"]
+//	[Result "1-0"]
+//	[Date "2026.10.17"]
+//	[Penalty "cell"]
+//	[MaxConquests "2"]
+//	[Protected "b2"]
+//
+//	1. X b2
+//	2. O a1
+//	3. X a1!
+//	4. O c3?
+//	5. X b1? -b2
+//
+// Cells are written as a column letter (a is x=0) followed by a row number (1 is y=0).
+// A "!" suffix marks a conquest won through a challenge, a "?" suffix a lost one.
+// A lost conquest may be followed by the cell the player lost as a penalty, prefixed by PrefixForfeit.
+// The conquest rule headers (Penalty, Cooldown, MaxConquests, Protected) are only written for rooms with conquest rules.
+const (
+	RecordDateFormat = "2006.01.02"
+
+	// Results
+	ResultXWins   = "1-0"
+	ResultOWins   = "0-1"
+	ResultDraw    = "1/2-1/2"
+	ResultOngoing = "*"
+
+	// Conquest suffixes
+	SuffixConquestWon  = "!"
+	SuffixConquestLost = "?"
+	PrefixForfeit      = "-"
+
+	// Header names
+	HeaderMode    = "Mode"
+	HeaderPlayerX = "PlayerX"
+	HeaderPlayerO = "PlayerO"
+	HeaderResult  = "Result"
+	HeaderDate    = "Date"
+
+	// Conquest rule headers
+	HeaderPenalty      = "Penalty"
+	HeaderCooldown     = "Cooldown"
+	HeaderMaxConquests = "MaxConquests"
+	HeaderProtected    = "Protected"
+
+	maxRecordColumns = 'z' - 'a' + 1
+)
+
+// Error messages
+var (
+	ErrInvalidRecord = errors.New("invalid game record")
+)
+
+var (
+	headerPattern = regexp.MustCompile(`^\[(\w+)\s+"(.*)"\]$`)
+	cellPattern   = regexp.MustCompile(`^([a-z])(\d+)$`)
+	movePattern   = regexp.MustCompile(`^(\d+)\.\s+([XO])\s+([a-z])(\d+)([!?]?)(?:\s+-([a-z]\d+))?$`)
+)
+
+// GameRecord is a finished (or ongoing) game, as written to a record file.
+type GameRecord struct {
+	Mode    string
+	PlayerX string
+	PlayerO string
+	Result  string
+	Date    time.Time
+	Moves   []MoveRecord
+
+	// Conquest rules of the room, the zero value for the default rules
+	Conquests common.ConquestRules
+}
+
+// NewGameRecord builds the record of a game.
+func NewGameRecord(mode string, game Rules, playerX, playerO string) *GameRecord {
+	return &GameRecord{
+		Mode:    mode,
+		PlayerX: playerX,
+		PlayerO: playerO,
+		Result:  resultOf(game),
+		Date:    time.Now(),
+		Moves:   game.History(),
+
+		Conquests: ConquestRulesOf(game),
+	}
+}
+
+// resultOf returns the result notation of a game.
+func resultOf(game Rules) string {
+	winner, over := game.Outcome()
+	switch {
+	case !over:
+		return ResultOngoing
+	case winner == common.P1:
+		return ResultXWins
+	case winner == common.P2:
+		return ResultOWins
+	default:
+		return ResultDraw
+	}
+}
+
+// FormatCell returns the notation of a cell, e.g. "b2" for (1, 1).
+func FormatCell(x, y int) string {
+	return fmt.Sprintf("%c%d", 'a'+x, y+1)
+}
+
+// ParseCell reads the notation of a cell, e.g. "b2" for (1, 1).
+func ParseCell(cell string) (Move, error) {
+	match := cellPattern.FindStringSubmatch(cell)
+	if match == nil {
+		return Move{}, fmt.Errorf("invalid cell '%s'", cell)
+	}
+
+	row, _ := strconv.Atoi(match[2])
+	if row < 1 {
+		return Move{}, fmt.Errorf("invalid row %d", row)
+	}
+	return Move{X: int(match[1][0] - 'a'), Y: row - 1}, nil
+}
+
+// FormatMove returns the notation of a move without its number, e.g. "X a1!".
+func FormatMove(move MoveRecord) string {
+	symbol := SymbolP1
+	if move.Player == common.P2 {
+		symbol = SymbolP2
+	}
+
+	suffix := ""
+	if move.Conquest && move.Won {
+		suffix = SuffixConquestWon
+	} else if move.Conquest {
+		suffix = SuffixConquestLost
+	}
+
+	if move.Forfeit != nil {
+		suffix += " " + PrefixForfeit + FormatCell(move.Forfeit.X, move.Forfeit.Y)
+	}
+
+	return fmt.Sprintf("%s %s%s", symbol, FormatCell(move.X, move.Y), suffix)
+}
+
+// Encode writes the record in the text format.
+func (r *GameRecord) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	headers := [][2]string{
+		{HeaderMode, r.Mode},
+		{HeaderPlayerX, r.PlayerX},
+		{HeaderPlayerO, r.PlayerO},
+		{HeaderResult, r.Result},
+		{HeaderDate, r.Date.Format(RecordDateFormat)},
+	}
+	headers = append(headers, r.conquestHeaders()...)
+	for _, header := range headers {
+		fmt.Fprintf(bw, "[%s %q]\n", header[0], header[1])
+	}
+	fmt.Fprintln(bw)
+
+	for i, move := range r.Moves {
+		if move.X >= maxRecordColumns {
+			return fmt.Errorf("%w: column %d cannot be written", ErrInvalidRecord, move.X)
+		}
+		fmt.Fprintf(bw, "%d. %s\n", i+1, FormatMove(move))
+	}
+
+	return bw.Flush()
+}
+
+// conquestHeaders returns the headers of the conquest rules that differ from the default rules.
+func (r *GameRecord) conquestHeaders() [][2]string {
+	var headers [][2]string
+	if r.Conquests.Penalty != "" {
+		headers = append(headers, [2]string{HeaderPenalty, r.Conquests.Penalty})
+	}
+	if r.Conquests.Cooldown > 0 {
+		headers = append(headers, [2]string{HeaderCooldown, strconv.Itoa(r.Conquests.Cooldown)})
+	}
+	if r.Conquests.MaxConquests > 0 {
+		headers = append(headers, [2]string{HeaderMaxConquests, strconv.Itoa(r.Conquests.MaxConquests)})
+	}
+	if len(r.Conquests.Protected) > 0 {
+		cells := make([]string, len(r.Conquests.Protected))
+		for i, cell := range r.Conquests.Protected {
+			cells[i] = FormatCell(cell.X, cell.Y)
+		}
+		headers = append(headers, [2]string{HeaderProtected, strings.Join(cells, " ")})
+	}
+	return headers
+}
+
+// String returns the record in the text format.
+func (r *GameRecord) String() string {
+	var sb strings.Builder
+	if err := r.Encode(&sb); err != nil {
+		return err.Error()
+	}
+	return sb.String()
+}
+
+// ParseGameRecord reads a record in the text format.
+// Unknown headers are ignored, moves must be numbered in order.
+func ParseGameRecord(reader io.Reader) (*GameRecord, error) {
+	record := &GameRecord{Result: ResultOngoing}
+	scanner := bufio.NewScanner(reader)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Headers
+		if match := headerPattern.FindStringSubmatch(line); match != nil {
+			if len(record.Moves) > 0 {
+				return nil, fmt.Errorf("%w: line %d: header after moves", ErrInvalidRecord, lineNumber)
+			}
+			if err := record.setHeader(match[1], match[2]); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRecord, lineNumber, err)
+			}
+			continue
+		}
+
+		// Moves
+		match := movePattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("%w: line %d: cannot parse '%s'", ErrInvalidRecord, lineNumber, line)
+		}
+
+		number, _ := strconv.Atoi(match[1])
+		if number != len(record.Moves)+1 {
+			return nil, fmt.Errorf("%w: line %d: expected move %d, got %d", ErrInvalidRecord, lineNumber, len(record.Moves)+1, number)
+		}
+
+		row, _ := strconv.Atoi(match[4])
+		if row < 1 {
+			return nil, fmt.Errorf("%w: line %d: invalid row %d", ErrInvalidRecord, lineNumber, row)
+		}
+
+		move := MoveRecord{
+			Player:   common.P1,
+			X:        int(match[3][0] - 'a'),
+			Y:        row - 1,
+			Conquest: match[5] != "",
+			Won:      match[5] == SuffixConquestWon,
+		}
+		if match[2] == SymbolP2 {
+			move.Player = common.P2
+		}
+		if match[6] != "" {
+			if match[5] != SuffixConquestLost {
+				return nil, fmt.Errorf("%w: line %d: cell lost without a lost conquest", ErrInvalidRecord, lineNumber)
+			}
+			forfeit, err := ParseCell(match[6])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidRecord, lineNumber, err)
+			}
+			move.Forfeit = &forfeit
+		}
+		record.Moves = append(record.Moves, move)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if record.Mode == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrInvalidRecord, HeaderMode)
+	}
+
+	return record, nil
+}
+
+// setHeader stores a known header value.
+func (r *GameRecord) setHeader(name, value string) error {
+	switch name {
+	case HeaderMode:
+		r.Mode = value
+	case HeaderPlayerX:
+		r.PlayerX = value
+	case HeaderPlayerO:
+		r.PlayerO = value
+	case HeaderResult:
+		switch value {
+		case ResultXWins, ResultOWins, ResultDraw, ResultOngoing:
+			r.Result = value
+		default:
+			return fmt.Errorf("unknown result '%s'", value)
+		}
+	case HeaderDate:
+		date, err := time.Parse(RecordDateFormat, value)
+		if err != nil {
+			return fmt.Errorf("invalid date '%s'", value)
+		}
+		r.Date = date
+	case HeaderPenalty:
+		if !slices.Contains(common.Penalties, value) {
+			return fmt.Errorf("unknown penalty '%s'", value)
+		}
+		r.Conquests.Penalty = value
+	case HeaderCooldown, HeaderMaxConquests:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s '%s'", name, value)
+		}
+		if name == HeaderCooldown {
+			r.Conquests.Cooldown = n
+		} else {
+			r.Conquests.MaxConquests = n
+		}
+	case HeaderProtected:
+		r.Conquests.Protected = nil
+		for _, field := range strings.Fields(value) {
+			cell, err := ParseCell(field)
+			if err != nil {
+				return err
+			}
+			r.Conquests.Protected = append(r.Conquests.Protected, common.Cell{X: cell.X, Y: cell.Y})
+		}
+	}
+	return nil
+}
+
+// Replay plays the record moves with the rules of its mode and its conquest rules, validating each of them.
+// onMove, if not nil, is called after each move with its index and the game state.
+// It returns the final game, or an error describing the first invalid move.
+func (r *GameRecord) Replay(onMove func(i int, move MoveRecord, game Rules)) (Rules, error) {
+	game, err := NewRules(r.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if game, err = WithConquestRules(game, r.Conquests); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+
+	for i, move := range r.Moves {
+		if err := replayMove(game, move); err != nil {
+			return game, fmt.Errorf("%w: move %d (%s): %v", ErrInvalidRecord, i+1, FormatMove(move), err)
+		}
+		if onMove != nil {
+			onMove(i, move, game)
+		}
+	}
+
+	// The result must match the final position
+	if result := resultOf(game); result != r.Result {
+		return game, fmt.Errorf("%w: result is %s but the game ends with %s", ErrInvalidRecord, r.Result, result)
+	}
+
+	return game, nil
+}
+
+// replayMove applies a recorded move, checking that conquests target an opponent cell.
+func replayMove(game Rules, move MoveRecord) error {
+	if game.CurrentTurn() != move.Player {
+		return ErrNotYourTurn
+	}
+
+	challenge := game.ShouldTriggerChallenge(move.Player, move.X, move.Y)
+	if challenge != move.Conquest {
+		if move.Conquest {
+			return errors.New("conquest of a cell that is not owned by the opponent")
+		}
+		return errors.New("move on an opponent cell without a conquest")
+	}
+
+	action := Action{Move: Move{X: move.X, Y: move.Y}, Conquest: move.Conquest}
+	if err := PlayAction(game, move.Player, action, move.Won); err != nil {
+		return err
+	}
+
+	// The conquest rules may already have taken the penalty cell
+	history := game.History()
+	if forfeit := history[len(history)-1].Forfeit; forfeit != nil {
+		if move.Forfeit == nil || *move.Forfeit != *forfeit {
+			return fmt.Errorf("the penalty takes %s, not the recorded cell", FormatCell(forfeit.X, forfeit.Y))
+		}
+		return nil
+	}
+	if move.Forfeit != nil {
+		return game.ForfeitCell(move.Forfeit.X, move.Forfeit.Y)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package logic
+
+import (
+	"Goonker/common"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGameRecordRoundTrip(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 0, 0)
+	game.DeleteMove(0, 0)
+	mustApply(t, game, common.P1, 0, 0) // Won conquest
+	mustApply(t, game, common.P2, 1, 1) // Lost conquest
+	mustApply(t, game, common.P1, 2, 2) // X wins on the diagonal
+
+	record := NewGameRecord(common.ModeClassic, game, "Alice", "Bot")
+	text := record.String()
+
+	for _, expected := range []string{`[Mode "classic"]`, `[Result "1-0"]`, "1. X b2", "3. X a1!", "4. O b2?", "5. X c3"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected record to contain %q, got:\n%s", expected, text)
+		}
+	}
+
+	parsed, err := ParseGameRecord(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if parsed.PlayerX != "Alice" || parsed.PlayerO != "Bot" || parsed.Result != ResultXWins {
+		t.Errorf("Unexpected headers %+v", parsed)
+	}
+	if len(parsed.Moves) != 5 || !parsed.Moves[2].Won || !parsed.Moves[3].Conquest || parsed.Moves[3].Won {
+		t.Fatalf("Unexpected moves %+v", parsed.Moves)
+	}
+
+	replayed := 0
+	final, err := parsed.Replay(func(i int, move MoveRecord, game Rules) { replayed++ })
+	if err != nil {
+		t.Fatalf("Expected valid replay, got %v", err)
+	}
+	if replayed != 5 {
+		t.Errorf("Expected 5 replayed moves, got %d", replayed)
+	}
+	if winner, over := final.Outcome(); !over || winner != common.P1 {
+		t.Errorf("Expected X to win the replay, got over=%v winner=%d", over, winner)
+	}
+}
+
+func TestReplayDetectsInvalidMoves(t *testing.T) {
+	records := map[string]string{
+		"wrong turn":      "[Mode \"classic\"]\n\n1. O a1\n",
+		"occupied cell":   "[Mode \"classic\"]\n\n1. X a1\n2. O b1\n3. X a1\n",
+		"fake conquest":   "[Mode \"classic\"]\n\n1. X a1!\n",
+		"wrong result":    "[Mode \"classic\"]\n[Result \"0-1\"]\n\n1. X a1\n",
+		"unknown mode":    "[Mode \"chess\"]\n\n1. X a1\n",
+		"missed conquest": "[Mode \"classic\"]\n\n1. X a1\n2. O a1\n",
+	}
+
+	for name, text := range records {
+		record, err := ParseGameRecord(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error %v", name, err)
+		}
+		if _, err := record.Replay(nil); err == nil {
+			t.Errorf("%s: expected replay to fail", name)
+		}
+	}
+}
+
+func TestParseGameRecordErrors(t *testing.T) {
+	records := []string{
+		"1. X a1\n",                                     // Missing mode
+		"[Mode \"classic\"]\n\n2. X a1\n",               // Wrong numbering
+		"[Mode \"classic\"]\n\n1. X a0\n",               // Invalid row
+		"[Mode \"classic\"]\n\n1. Z a1\n",               // Unknown symbol
+		"[Mode \"classic\"]\n[Result \"2-0\"]\n\n",      // Unknown result
+		"[Mode \"classic\"]\n\n1. X a1! -b2\n",          // Lost cell without a lost conquest
+		"[Mode \"classic\"]\n[Penalty \"jail\"]\n\n",    // Unknown penalty
+		"[Mode \"classic\"]\n[MaxConquests \"-1\"]\n\n", // Invalid conquest limit
+		"[Mode \"classic\"]\n[Protected \"b0\"]\n\n",    // Invalid protected cell
+		"[Mode \"classic\"]\n\n1. X a1\n[Date \"2026.01.01\"]\n",
+	}
+
+	for _, text := range records {
+		if _, err := ParseGameRecord(strings.NewReader(text)); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("Expected ErrInvalidRecord for %q, got %v", text, err)
+		}
+	}
+}
+
+func TestUltimateRecordReplay(t *testing.T) {
+	game := NewUltimateLogic()
+	mustApply(t, game, common.P1, 4, 4)
+	mustApply(t, game, common.P2, 3, 5)
+
+	record := NewGameRecord(common.ModeUltimate, game, "Alice", "Bob")
+	parsed, err := ParseGameRecord(strings.NewReader(record.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parsed.Replay(nil); err != nil {
+		t.Errorf("Expected valid replay, got %v", err)
+	}
+}
+
+func TestConquestRulesRecord(t *testing.T) {
+	conquests := common.ConquestRules{Penalty: common.PenaltyCooldown, Cooldown: 1, MaxConquests: 2, Protected: []common.Cell{{X: 1, Y: 1}, {X: 0, Y: 2}}}
+	game := newConquestGame(t, conquests)
+	mustApply(t, game, common.P1, 1, 1)
+	mustApply(t, game, common.P2, 0, 0)
+	mustApply(t, game, common.P1, 0, 0) // Lost conquest
+
+	text := NewGameRecord(common.ModeClassic, game, "Alice", "Bob").String()
+	for _, expected := range []string{`[Penalty "cooldown"]`, `[Cooldown "1"]`, `[MaxConquests "2"]`, `[Protected "b2 a3"]`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected record to contain %q, got:\n%s", expected, text)
+		}
+	}
+
+	parsed, err := ParseGameRecord(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Failed to parse record: %v", err)
+	}
+	if parsed.Conquests.Penalty != conquests.Penalty || parsed.Conquests.Cooldown != conquests.Cooldown ||
+		parsed.Conquests.MaxConquests != conquests.MaxConquests || !slices.Equal(parsed.Conquests.Protected, conquests.Protected) {
+		t.Errorf("Expected the conquest rules %+v, got %+v", conquests, parsed.Conquests)
+	}
+	replayed, err := parsed.Replay(nil)
+	if err != nil {
+		t.Fatalf("Expected valid replay, got %v", err)
+	}
+	if rules := ConquestRulesOf(replayed); rules.Penalty != conquests.Penalty || len(rules.Protected) != 2 {
+		t.Errorf("Expected the replay to enforce the conquest rules, got %+v", rules)
+	}
+
+	// The conquest of a protected cell is only valid without the rules
+	protected := "[Mode \"classic\"]\n%s\n1. X b2\n2. O a1\n3. X c3\n4. O b2?\n"
+	if record, err := ParseGameRecord(strings.NewReader(fmt.Sprintf(protected, "[Protected \"b2\"]\n"))); err != nil {
+		t.Fatal(err)
+	} else if _, err := record.Replay(nil); err == nil {
+		t.Error("Expected the conquest of a protected cell to fail the replay")
+	}
+	if record, err := ParseGameRecord(strings.NewReader(fmt.Sprintf(protected, ""))); err != nil {
+		t.Fatal(err)
+	} else if _, err := record.Replay(nil); err != nil {
+		t.Errorf("Expected valid replay without conquest rules, got %v", err)
+	}
+
+	// Default rules write no conquest header
+	if text := NewGameRecord(common.ModeClassic, NewGameLogic(ClassicRules), "Alice", "Bob").String(); strings.Contains(text, HeaderPenalty) {
+		t.Errorf("Expected no conquest headers for the default rules, got:\n%s", text)
+	}
+}
@@ -0,0 +1,174 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"Goonker/common"
+)
+
+// Error messages
+var (
+	ErrUnknownMode  = errors.New("unknown game mode")
+	ErrNotAConquest = errors.New("not a conquest of an opponent cell")
+)
+
+// Move is a cell of the board, in the coordinates used by ApplyMove.
+type Move struct {
+	X int
+	Y int
+}
+
+// Rules is implemented by every game variant a Room can host.
+type Rules interface {
+	// CurrentTurn returns the player expected to move.
+	CurrentTurn() common.PlayerID
+
+	// LegalMoves lists the empty cells the current player may claim directly.
+	// Cells owned by the opponent are conquered through ShouldTriggerChallenge instead.
+	LegalMoves() []Move
+
+	// ConquestMoves lists the opponent cells the current player may try to conquer.
+	ConquestMoves() []Move
+
+	// ShouldTriggerChallenge checks if the move must be won through a challenge.
+	ShouldTriggerChallenge(player common.PlayerID, x, y int) bool
+
+	// ApplyMove plays a move, playing on an opponent cell only passes the turn.
+	ApplyMove(player common.PlayerID, x, y int) error
+
+	// DeleteMove empties a cell, before replaying it after a won challenge.
+	DeleteMove(x, y int)
+
+	// RestoreDeleted gives back the cell emptied by DeleteMove, when the conquering move is refused.
+	RestoreDeleted()
+
+	// ForfeitCell empties a cell of the player of the last move, as the penalty of a failed conquest.
+	ForfeitCell(x, y int) error
+
+	// Undo reverts the last move, including challenge conquests.
+	Undo() error
+
+	// History returns the ordered move log of the game.
+	History() []MoveRecord
+
+	// Outcome returns the winner (Empty for a draw) and whether the game is over.
+	Outcome() (winner common.PlayerID, over bool)
+
+	// State serializes the game for the clients.
+	State() common.UpdatePayload
+
+	// Clone returns an independent copy of the game.
+	Clone() Rules
+
+	// PrintConsoleBoard renders the board to the console for debugging.
+	PrintConsoleBoard()
+}
+
+// RulesFactory creates a new game of a given variant.
+type RulesFactory func() Rules
+
+// registry holds the available game variants, keyed by mode name.
+var registry = struct {
+	sync.RWMutex
+	factories map[string]RulesFactory
+	modes     []string // Registration order
+}{
+	factories: make(map[string]RulesFactory),
+}
+
+// Built-in game variants
+func init() {
+	RegisterRules(common.ModeClassic, func() Rules { return NewGameLogic(ClassicRules) })
+	RegisterRules(common.ModeUltimate, func() Rules { return NewUltimateLogic() })
+}
+
+// RegisterRules makes a game variant available under the given mode name.
+// It panics if the mode is already registered.
+func RegisterRules(mode string, factory RulesFactory) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.factories[mode]; exists {
+		panic(fmt.Sprintf("game mode '%s' registered twice", mode))
+	}
+	registry.factories[mode] = factory
+	registry.modes = append(registry.modes, mode)
+}
+
+// NewRules creates a new game for the given mode.
+func NewRules(mode string) (Rules, error) {
+	registry.RLock()
+	factory, ok := registry.factories[mode]
+	registry.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownMode, mode)
+	}
+	return factory(), nil
+}
+
+// Modes returns the registered mode names, in registration order.
+func Modes() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]string(nil), registry.modes...)
+}
+
+// Opponent returns the other player.
+func Opponent(player common.PlayerID) common.PlayerID {
+	if player == common.P1 {
+		return common.P2
+	}
+	return common.P1
+}
+
+// Action is a choice of the player to move: claiming an empty cell or trying to conquer an opponent cell.
+type Action struct {
+	Move
+	Conquest bool
+}
+
+// Actions lists the legal moves of the current player, followed by its conquest attempts when conquests is set.
+func Actions(game Rules, conquests bool) []Action {
+	var actions []Action
+	for _, move := range game.LegalMoves() {
+		actions = append(actions, Action{Move: move})
+	}
+	if conquests {
+		for _, move := range game.ConquestMoves() {
+			actions = append(actions, Action{Move: move, Conquest: true})
+		}
+	}
+	return actions
+}
+
+// PlayAction applies an action of player, won tells whether a conquest attempt succeeded.
+// A lost conquest only passes the turn, like a wrong answer to the challenge.
+// Conquests are checked before the cell is emptied, so that a refused conquest leaves the game untouched.
+func PlayAction(game Rules, player common.PlayerID, action Action, won bool) error {
+	if action.Conquest {
+		if _, over := game.Outcome(); over {
+			return ErrGameOver
+		}
+		if game.CurrentTurn() != player {
+			return ErrNotYourTurn
+		}
+		if !game.ShouldTriggerChallenge(player, action.X, action.Y) {
+			return ErrNotAConquest
+		}
+	}
+
+	deleted := action.Conquest && won
+	if deleted {
+		game.DeleteMove(action.X, action.Y)
+	}
+	if err := game.ApplyMove(player, action.X, action.Y); err != nil {
+		if deleted {
+			game.RestoreDeleted()
+		}
+		return err
+	}
+	return nil
+}
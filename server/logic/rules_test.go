@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"Goonker/common"
+	"errors"
+	"testing"
+)
+
+func TestNewRules(t *testing.T) {
+	for _, mode := range []string{common.ModeClassic, common.ModeUltimate} {
+		rules, err := NewRules(mode)
+		if err != nil {
+			t.Fatalf("Expected mode %s to be registered, got %v", mode, err)
+		}
+		if rules.CurrentTurn() != common.P1 {
+			t.Errorf("Expected P1 to start in mode %s", mode)
+		}
+	}
+
+	if _, err := NewRules("unknown"); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("Expected ErrUnknownMode, got %v", err)
+	}
+}
+
+func TestModes(t *testing.T) {
+	modes := Modes()
+	if len(modes) < 2 || modes[0] != common.ModeClassic || modes[1] != common.ModeUltimate {
+		t.Errorf("Expected built-in modes first, got %v", modes)
+	}
+
+	// The returned slice is a copy
+	modes[0] = "changed"
+	if Modes()[0] != common.ModeClassic {
+		t.Error("Expected Modes to return a copy")
+	}
+}
+
+func TestRegisterRulesTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when registering a mode twice")
+		}
+	}()
+	RegisterRules(common.ModeClassic, func() Rules { return NewGameLogic(ClassicRules) })
+}
+
+func TestLegalMoves(t *testing.T) {
+	classic := NewGameLogic(ClassicRules)
+	mustApply(t, classic, common.P1, 1, 1)
+	if moves := classic.LegalMoves(); len(moves) != 8 {
+		t.Errorf("Expected 8 legal moves, got %d", len(moves))
+	}
+
+	// Ultimate moves are restricted to the active sub-board
+	ultimate := NewUltimateLogic()
+	if moves := ultimate.LegalMoves(); len(moves) != UltimateSize*UltimateSize {
+		t.Errorf("Expected %d legal moves on the first move, got %d", UltimateSize*UltimateSize, len(moves))
+	}
+	mustApply(t, ultimate, common.P1, 4, 4)
+	if moves := ultimate.LegalMoves(); len(moves) != 8 {
+		t.Errorf("Expected 8 legal moves in the center sub-board, got %d", len(moves))
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	var game Rules = NewUltimateLogic()
+	clone := game.Clone()
+	if err := clone.ApplyMove(common.P1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if game.State().Board[0][0] != common.Empty {
+		t.Error("Expected the original game to be unchanged")
+	}
+	if game.CurrentTurn() != common.P1 {
+		t.Error("Expected the original turn to be unchanged")
+	}
+}
@@ -0,0 +1,462 @@
+package logic
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"Goonker/common"
+)
+
+// Search constants
+const (
+	// Default time after which the iterative deepening stops
+	DefaultTimeBudget = time.Second
+
+	// The transposition table is cleared when it grows beyond MaxTableEntries
+	MaxTableEntries = 1 << 20
+
+	// The deadline is checked every deadlineCheckNodes nodes
+	deadlineCheckNodes = 1024
+
+	// Failed conquests only pass the turn, so games with conquests are not bounded
+	// by the board: their search stops at maxConquestDepthFactor times the number of cells
+	maxConquestDepthFactor = 4
+)
+
+// SearchOptions limit the search of a Searcher.
+type SearchOptions struct {
+	MaxDepth   int           // Plies searched at most, 0 for no limit
+	TimeBudget time.Duration // Time after which no deeper iteration is searched, 0 for no limit
+
+	// Probability of winning the challenge of a conquest, conquests are not tried when 0.
+	// Conquests are scored with the expected score of their two outcomes.
+	ConquestProbability float64
+}
+
+// DefaultSearchOptions are the options of the server bot.
+var DefaultSearchOptions = SearchOptions{TimeBudget: DefaultTimeBudget}
+
+// SearchResult is the outcome of a search.
+type SearchResult struct {
+	Move     Move // InvalidCoord when there is no legal move
+	Conquest bool // The move is an attempt to conquer an opponent cell
+	Score    int  // From the point of view of the player to move
+	Depth    int  // Depth of the last completed iteration, 0 when answered by the tablebase
+	Nodes    int  // Number of positions visited
+	Solved   bool // The score is exact, no position was cut at the horizon
+}
+
+// ActionScore is an action with its score for the player to move.
+type ActionScore struct {
+	Action
+	Score int
+}
+
+// Transposition table bound types
+type tableFlag uint8
+
+const (
+	tableExact tableFlag = iota
+	tableLower           // The score is at least the stored one
+	tableUpper           // The score is at most the stored one
+)
+
+// tableEntry is a position searched by a previous call, keyed by its Zobrist hash.
+type tableEntry struct {
+	depth   int
+	score   int
+	flag    tableFlag
+	action  Action
+	horizon bool // The score relies on evaluated positions
+}
+
+// Searcher finds moves with an alpha-beta negamax search and iterative deepening.
+// Its transposition table is kept between calls, a Searcher should only be used for one variant.
+type Searcher struct {
+	mutex   sync.Mutex
+	options SearchOptions
+	table   map[uint64]tableEntry
+
+	// State of the running search
+	ctx      context.Context
+	deadline time.Time
+	canAbort bool
+	aborted  bool
+	horizon  bool
+	nodes    int
+}
+
+// NewSearcher creates a searcher with an empty transposition table.
+func NewSearcher(options SearchOptions) *Searcher {
+	return &Searcher{
+		options: options,
+		table:   make(map[uint64]tableEntry),
+	}
+}
+
+// Search returns the best move for the player to move in game, which is left untouched.
+// The search stops at the deadline of ctx or when it is cancelled.
+func (s *Searcher) Search(ctx context.Context, game Rules) SearchResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := SearchResult{Move: Move{X: InvalidCoord, Y: InvalidCoord}}
+	actions := Actions(game, s.conquests())
+	if len(actions) == 0 {
+		return result
+	}
+	// Positions of the tablebase are answered at once
+	if scores, ok := s.lookup(game, actions); ok {
+		result.Move, result.Conquest, result.Score = scores[0].Move, scores[0].Conquest, scores[0].Score
+		result.Solved = true
+		return result
+	}
+
+	best := actions[0]
+
+	// Moves are played and undone on a private copy
+	game, maxDepth := s.start(ctx, game)
+	player := game.CurrentTurn()
+
+	for depth := 1; depth <= maxDepth; depth++ {
+		// The first iteration ignores the time budget, so that a move is found, only ctx stops it
+		s.canAbort = depth > 1
+		s.horizon = false
+
+		action, score := s.searchRoot(game, player, actions, best, depth)
+		if s.aborted {
+			break
+		}
+		best = action
+		result.Score, result.Depth = score, depth
+
+		// Deeper iterations cannot change a proven result, expected scores are never proven
+		if !s.horizon || (!s.conquests() && isProvenScore(score)) {
+			result.Solved = true
+			break
+		}
+	}
+	result.Move, result.Conquest = best.Move, best.Conquest
+	result.Nodes = s.nodes
+	return result
+}
+
+// Analyze scores every action of the player to move in game, which is left untouched.
+// Unlike Search, each action gets its exact score at the searched depth, best first.
+// The analysis stops like Search, nil is returned when ctx is done before the first iteration.
+func (s *Searcher) Analyze(ctx context.Context, game Rules) []ActionScore {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	actions := Actions(game, s.conquests())
+	if len(actions) == 0 {
+		return nil
+	}
+
+	if scores, ok := s.lookup(game, actions); ok {
+		return scores
+	}
+
+	game, maxDepth := s.start(ctx, game)
+	player := game.CurrentTurn()
+
+	var scores []ActionScore
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.canAbort = depth > 1
+		s.horizon = false
+
+		// Full windows, so that no action is only bounded
+		current := make([]ActionScore, 0, len(actions))
+		for _, action := range actions {
+			score, ok := s.searchAction(game, player, action, depth, 0, -WinScore, WinScore)
+			if s.aborted {
+				break
+			}
+			if ok {
+				current = append(current, ActionScore{Action: action, Score: score})
+			}
+		}
+		if s.aborted {
+			break
+		}
+		scores = current
+
+		if !s.horizon {
+			break
+		}
+	}
+
+	slices.SortStableFunc(scores, func(a, b ActionScore) int {
+		return b.Score - a.Score
+	})
+	return scores
+}
+
+// lookup scores the actions of game with the default tablebase, best first.
+// Depth limited searches never use it, their weakness is deliberate, and neither do searches
+// of conquests, since the table assumes that no conquest follows the scored actions.
+func (s *Searcher) lookup(game Rules, actions []Action) ([]ActionScore, bool) {
+	table := DefaultTablebase()
+	if table == nil || s.options.MaxDepth > 0 || s.conquests() {
+		return nil, false
+	}
+	return table.ScoreActions(game, actions, s.options.ConquestProbability)
+}
+
+// start prepares a new search of game, it returns the copy of game to search and the depth limit.
+func (s *Searcher) start(ctx context.Context, game Rules) (Rules, int) {
+	s.nodes = 0
+	s.aborted = false
+	s.ctx = ctx
+	s.deadline = searchDeadline(ctx, s.options.TimeBudget)
+	if len(s.table) > MaxTableEntries {
+		s.table = make(map[uint64]tableEntry)
+	}
+
+	// Without conquests, a game cannot last longer than its number of empty cells
+	board := game.State().Board
+	maxDepth := countEmptyCells(board)
+	if s.conquests() {
+		maxDepth = maxConquestDepthFactor * len(board) * len(board[0])
+	}
+	if s.options.MaxDepth > 0 {
+		maxDepth = min(maxDepth, s.options.MaxDepth)
+	}
+	return game.Clone(), max(maxDepth, 1)
+}
+
+// conquests checks if conquest attempts are searched.
+func (s *Searcher) conquests() bool {
+	return s.options.ConquestProbability > 0
+}
+
+// searchRoot searches every root action to the given depth, starting with the previous best action.
+func (s *Searcher) searchRoot(game Rules, player common.PlayerID, actions []Action, first Action, depth int) (Action, int) {
+	orderActions(actions, first)
+
+	alpha, beta := -WinScore, WinScore
+	bestAction, bestScore := actions[0], -WinScore
+	for _, action := range actions {
+		score, ok := s.searchAction(game, player, action, depth, 0, alpha, beta)
+		if s.aborted {
+			break
+		}
+		if !ok {
+			continue
+		}
+		if score > bestScore {
+			bestAction, bestScore = action, score
+		}
+		alpha = max(alpha, score)
+	}
+	return bestAction, bestScore
+}
+
+// searchAction returns the score for player of playing action in a node at the given depth and ply.
+// A conquest attempt scores the expected score of its won and lost outcomes, each searched with a full window.
+func (s *Searcher) searchAction(game Rules, player common.PlayerID, action Action, depth, ply, alpha, beta int) (int, bool) {
+	if !action.Conquest {
+		return s.searchOutcome(game, player, action, false, depth, ply, alpha, beta)
+	}
+
+	expected := 0.0
+	for _, outcome := range outcomes(action, s.options.ConquestProbability) {
+		if outcome.probability <= 0 {
+			continue
+		}
+		score, ok := s.searchOutcome(game, player, action, outcome.won, depth, ply, -WinScore, WinScore)
+		if !ok {
+			return 0, false
+		}
+		expected += outcome.probability * float64(score)
+	}
+	return int(expected), true
+}
+
+// searchDeadline returns the end of a time budget started now, or the deadline of ctx if it comes first.
+// The zero time means no limit.
+func searchDeadline(ctx context.Context, budget time.Duration) time.Time {
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	return deadline
+}
+
+// actionOutcome is a result of an action with its probability.
+type actionOutcome struct {
+	won         bool
+	probability float64
+}
+
+// outcomes lists the results of an action: a conquest is won with probability p, other actions have a single result.
+func outcomes(action Action, p float64) []actionOutcome {
+	if !action.Conquest {
+		return []actionOutcome{{won: false, probability: 1}}
+	}
+	return []actionOutcome{{won: true, probability: p}, {won: false, probability: 1 - p}}
+}
+
+// searchOutcome plays an action with the given conquest outcome, searches the position and undoes the action.
+func (s *Searcher) searchOutcome(game Rules, player common.PlayerID, action Action, won bool, depth, ply, alpha, beta int) (int, bool) {
+	if err := PlayAction(game, player, action, won); err != nil {
+		return 0, false
+	}
+	score := -s.negamax(game, Opponent(player), depth-1, ply+1, -beta, -alpha)
+	game.Undo()
+	return score, true
+}
+
+// negamax returns the score of the position for player, the player to move.
+// ply is the distance to the root, so that faster wins score higher.
+func (s *Searcher) negamax(game Rules, player common.PlayerID, depth, ply, alpha, beta int) int {
+	s.nodes++
+	if s.nodes%deadlineCheckNodes == 0 {
+		// The context is a hard limit, the time budget lets the first iteration complete
+		if s.ctx.Err() != nil || (s.canAbort && !s.deadline.IsZero() && time.Now().After(s.deadline)) {
+			s.aborted = true
+		}
+	}
+	if s.aborted {
+		return 0
+	}
+
+	// Check for win, loss or draw
+	if winner, over := game.Outcome(); over {
+		switch winner {
+		case common.Empty:
+			return 0
+		case player:
+			return WinScore - ply
+		default:
+			return ply - WinScore
+		}
+	}
+
+	// Unknown result at the search horizon
+	if depth == 0 {
+		s.horizon = true
+		return evaluate(game, player)
+	}
+
+	// Look up the position in the transposition table
+	hasher, hashable := game.(Hasher)
+	var hash uint64
+	first := Action{Move: Move{X: InvalidCoord, Y: InvalidCoord}}
+	if hashable {
+		hash = hasher.Hash()
+		if entry, ok := s.table[hash]; ok {
+			first = entry.action
+			if entry.depth >= depth || !entry.horizon {
+				score := scoreFromTable(entry.score, ply)
+				if entry.flag == tableExact ||
+					(entry.flag == tableLower && score >= beta) ||
+					(entry.flag == tableUpper && score <= alpha) {
+					s.horizon = s.horizon || entry.horizon
+					return score
+				}
+			}
+		}
+	}
+
+	actions := Actions(game, s.conquests())
+	if len(actions) == 0 {
+		return 0
+	}
+	orderActions(actions, first)
+
+	// Track the horizon of this subtree only
+	outerHorizon := s.horizon
+	s.horizon = false
+
+	alphaOrig := alpha
+	bestAction, bestScore := actions[0], -WinScore
+	for _, action := range actions {
+		score, ok := s.searchAction(game, player, action, depth, ply, alpha, beta)
+		if s.aborted {
+			return 0
+		}
+		if !ok {
+			continue
+		}
+		if score > bestScore {
+			bestAction, bestScore = action, score
+		}
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break // The opponent will avoid this position
+		}
+	}
+
+	if hashable {
+		entry := tableEntry{
+			depth:   depth,
+			score:   scoreToTable(bestScore, ply),
+			action:  bestAction,
+			horizon: s.horizon,
+		}
+		switch {
+		case bestScore <= alphaOrig:
+			entry.flag = tableUpper
+		case bestScore >= beta:
+			entry.flag = tableLower
+		default:
+			entry.flag = tableExact
+		}
+		s.table[hash] = entry
+	}
+
+	s.horizon = s.horizon || outerHorizon
+	return bestScore
+}
+
+// evaluate scores an unfinished position for player, unknown positions count as a draw.
+func evaluate(game Rules, player common.PlayerID) int {
+	if evaluator, ok := game.(Evaluator); ok {
+		return evaluator.Evaluate(player)
+	}
+	return 0
+}
+
+// isProvenScore checks if a score is a forced win or loss rather than an evaluation.
+func isProvenScore(score int) bool {
+	return score > MaxEvaluation || score < -MaxEvaluation
+}
+
+// scoreToTable makes a proven score relative to the stored position instead of the root.
+func scoreToTable(score, ply int) int {
+	switch {
+	case score > MaxEvaluation:
+		return score + ply
+	case score < -MaxEvaluation:
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTable makes a stored proven score relative to the root again.
+func scoreFromTable(score, ply int) int {
+	switch {
+	case score > MaxEvaluation:
+		return score - ply
+	case score < -MaxEvaluation:
+		return score + ply
+	}
+	return score
+}
+
+// orderActions moves first to the front of actions, keeping the other actions in order.
+func orderActions(actions []Action, first Action) {
+	for i, action := range actions {
+		if action == first {
+			copy(actions[1:i+1], actions[:i])
+			actions[0] = first
+			return
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Goonker/common"
+)
+
+// referenceNegamax is a plain negamax without pruning nor table, used to check the searcher.
+func referenceNegamax(game Rules, player common.PlayerID, ply int) int {
+	if winner, over := game.Outcome(); over {
+		switch winner {
+		case common.Empty:
+			return 0
+		case player:
+			return WinScore - ply
+		default:
+			return ply - WinScore
+		}
+	}
+
+	best := -WinScore
+	for _, move := range game.LegalMoves() {
+		child := game.Clone()
+		if err := child.ApplyMove(player, move.X, move.Y); err != nil {
+			continue
+		}
+		best = max(best, -referenceNegamax(child, Opponent(player), ply+1))
+	}
+	return best
+}
+
+func TestSearchEmptyBoardIsDraw(t *testing.T) {
+	result := NewSearcher(SearchOptions{}).Search(context.Background(), NewGameLogic(ClassicRules))
+	if !result.Solved {
+		t.Fatal("Expected the 3x3 board to be solved")
+	}
+	if result.Score != 0 {
+		t.Errorf("Expected a draw score, got %d", result.Score)
+	}
+}
+
+func TestSearchMatchesReference(t *testing.T) {
+	// A searcher reused over every position, to exercise the table between calls
+	searcher := NewSearcher(SearchOptions{})
+	openings := [][]Move{
+		{},
+		{{1, 1}},
+		{{0, 0}, {1, 1}},
+		{{0, 0}, {2, 2}, {1, 0}},
+		{{1, 1}, {0, 1}, {2, 2}},
+		{{0, 1}, {1, 1}, {1, 0}, {2, 2}},
+	}
+
+	for _, opening := range openings {
+		game := NewGameLogic(ClassicRules)
+		for _, move := range opening {
+			mustApply(t, game, game.Turn, move.X, move.Y)
+		}
+
+		result := searcher.Search(context.Background(), game)
+		expected := referenceNegamax(game, game.Turn, 0)
+		if result.Score != expected {
+			t.Errorf("Opening %v: expected score %d, got %d", opening, expected, result.Score)
+		}
+
+		// The chosen move must reach the same score
+		child := game.Clone()
+		if err := child.ApplyMove(game.Turn, result.Move.X, result.Move.Y); err != nil {
+			t.Fatalf("Opening %v: illegal move %v: %v", opening, result.Move, err)
+		}
+		if score := -referenceNegamax(child, Opponent(game.Turn), 1); score != expected {
+			t.Errorf("Opening %v: move %v scores %d, expected %d", opening, result.Move, score, expected)
+		}
+	}
+}
+
+func TestAnalyzeMatchesReference(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	mustApply(t, game, common.P1, 0, 0)
+	mustApply(t, game, common.P2, 1, 0)
+
+	scores := NewSearcher(SearchOptions{}).Analyze(context.Background(), game)
+	if len(scores) != len(game.LegalMoves()) {
+		t.Fatalf("Expected a score for each of the %d legal moves, got %d", len(game.LegalMoves()), len(scores))
+	}
+
+	for i, score := range scores {
+		if i > 0 && score.Score > scores[i-1].Score {
+			t.Errorf("Expected the moves best first, got %+v", scores)
+		}
+
+		child := game.Clone()
+		if err := child.ApplyMove(common.P1, score.Move.X, score.Move.Y); err != nil {
+			t.Fatalf("Illegal move %v: %v", score.Move, err)
+		}
+		if expected := -referenceNegamax(child, common.P2, 1); score.Score != expected {
+			t.Errorf("Move %v: expected score %d, got %d", score.Move, expected, score.Score)
+		}
+	}
+}
+
+func TestSearchPlaysEitherSide(t *testing.T) {
+	/*
+	   X X .
+	   O O .
+	   . . .
+	   Turn: X
+	   Expected: 2,0 (win)
+	*/
+	game := NewGameLogic(ClassicRules)
+	game.Board[0][0] = common.P1
+	game.Board[1][0] = common.P1
+	game.Board[0][1] = common.P2
+	game.Board[1][1] = common.P2
+	game.SymbolCount = 4
+
+	result := NewSearcher(SearchOptions{}).Search(context.Background(), game)
+	if result.Move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected winning move at 2,0, got %v", result.Move)
+	}
+	if result.Score != WinScore-1 {
+		t.Errorf("Expected an immediate win score, got %d", result.Score)
+	}
+}
+
+func TestSearchMaxDepth(t *testing.T) {
+	result := NewSearcher(SearchOptions{MaxDepth: 2}).Search(context.Background(), NewGameLogic(ClassicRules))
+	if result.Depth != 2 {
+		t.Errorf("Expected the search to stop at depth 2, got %d", result.Depth)
+	}
+	if result.Solved {
+		t.Error("Expected a depth limited search not to be solved")
+	}
+}
+
+func TestSearchTimeBudget(t *testing.T) {
+	budget := 50 * time.Millisecond
+	game := NewUltimateLogic()
+
+	start := time.Now()
+	result := NewSearcher(SearchOptions{TimeBudget: budget}).Search(context.Background(), game)
+	if elapsed := time.Since(start); elapsed > 10*budget {
+		t.Errorf("Expected the search to respect its budget, took %v", elapsed)
+	}
+
+	if result.Depth < 1 {
+		t.Error("Expected at least one completed iteration")
+	}
+	if err := game.ApplyMove(common.P1, result.Move.X, result.Move.Y); err != nil {
+		t.Errorf("Expected a legal move, got %v: %v", result.Move, err)
+	}
+}
+
+func TestSearchContextDeadline(t *testing.T) {
+	timeout := 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	game := NewUltimateLogic()
+
+	// The deadline of the context comes before the time budget
+	start := time.Now()
+	result := NewSearcher(SearchOptions{TimeBudget: time.Minute}).Search(ctx, game)
+	if elapsed := time.Since(start); elapsed > 10*timeout {
+		t.Errorf("Expected the search to respect the context deadline, took %v", elapsed)
+	}
+	if err := game.ApplyMove(common.P1, result.Move.X, result.Move.Y); err != nil {
+		t.Errorf("Expected a legal move, got %v: %v", result.Move, err)
+	}
+}
+
+func TestSearchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	NewSearcher(SearchOptions{}).Search(ctx, NewUltimateLogic())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a cancelled search to stop, took %v", elapsed)
+	}
+}
+
+func TestSearchNoLegalMove(t *testing.T) {
+	game := NewGameLogic(ClassicRules)
+	game.GameOver = true
+
+	result := NewSearcher(DefaultSearchOptions).Search(context.Background(), game)
+	if result.Move.X != InvalidCoord || result.Move.Y != InvalidCoord {
+		t.Errorf("Expected no move, got %v", result.Move)
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	a := NewGameLogic(ClassicRules)
+	mustApply(t, a, common.P1, 0, 0)
+	mustApply(t, a, common.P2, 1, 1)
+	mustApply(t, a, common.P1, 2, 2)
+
+	b := NewGameLogic(ClassicRules)
+	mustApply(t, b, common.P1, 2, 2)
+	mustApply(t, b, common.P2, 1, 1)
+	mustApply(t, b, common.P1, 0, 0)
+
+	if a.Hash() != b.Hash() {
+		t.Error("Expected the same position to have the same hash")
+	}
+
+	// The player to move is part of the position
+	b.Turn = common.P1
+	if a.Hash() == b.Hash() {
+		t.Error("Expected the player to move to change the hash")
+	}
+
+	// Boards of different rules never share keys
+	short := NewGameLogic(BoardRules{Width: 3, Height: 3, WinLength: 2})
+	if short.Hash() != 0 || NewGameLogic(ClassicRules).Hash() != 0 {
+		t.Error("Expected the empty board with X to move to hash to zero")
+	}
+	mustApply(t, short, common.P1, 0, 0)
+	classic := NewGameLogic(ClassicRules)
+	mustApply(t, classic, common.P1, 0, 0)
+	if short.Hash() == classic.Hash() {
+		t.Error("Expected different rules to hash differently")
+	}
+}
+
+func TestHashUltimateActiveBoard(t *testing.T) {
+	a := NewUltimateLogic()
+	b := NewUltimateLogic()
+	b.ActiveX, b.ActiveY = 1, 1
+
+	if a.Hash() == b.Hash() {
+		t.Error("Expected the active sub-board to change the hash")
+	}
+}
+
+func TestHashUltimateSubBoardWinners(t *testing.T) {
+	// A sub-board stays won after a conquest or a penalty empties one of its cells
+	a := NewUltimateLogic()
+	a.Board[0][0], a.Board[1][0] = common.P1, common.P1
+	b := a.Clone().(*UltimateLogic)
+	b.SubBoards[0][0] = common.P1
+
+	won := b.Hash()
+	if a.Hash() == won {
+		t.Error("Expected the sub-board winners to change the hash")
+	}
+	b.SubBoards[0][0] = common.P2
+	if b.Hash() == won || b.Hash() == a.Hash() {
+		t.Error("Expected each winner to hash differently")
+	}
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+	game := NewGameLogic(BoardRules{Width: 5, Height: 5, WinLength: 4})
+	mustApply(t, game, common.P1, 2, 2)
+	mustApply(t, game, common.P2, 0, 0)
+	mustApply(t, game, common.P1, 2, 3)
+
+	x, o := game.Evaluate(common.P1), game.Evaluate(common.P2)
+	if x != -o {
+		t.Errorf("Expected opposite evaluations, got %d and %d", x, o)
+	}
+	if x <= 0 {
+		t.Errorf("Expected X to be ahead, got %d", x)
+	}
+	if isProvenScore(x) {
+		t.Errorf("Expected an evaluation below proven scores, got %d", x)
+	}
+}
+
+func TestSearchConquest(t *testing.T) {
+	// A sure conquest of 2,0 wins the row at once
+	result := NewSearcher(SearchOptions{MaxDepth: 4, ConquestProbability: 1}).Search(context.Background(), conquestPosition())
+	if !result.Conquest || result.Move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected a conquest of 2,0, got %+v", result)
+	}
+	if result.Score != WinScore-1 {
+		t.Errorf("Expected an immediate win score, got %d", result.Score)
+	}
+
+	// An unlikely conquest leaves the diagonal of O open, X must block it
+	result = NewSearcher(SearchOptions{MaxDepth: 4, ConquestProbability: 0.01}).Search(context.Background(), conquestPosition())
+	if result.Conquest || result.Move != (Move{X: 0, Y: 2}) {
+		t.Errorf("Expected a block at 0,2, got %+v", result)
+	}
+}
+
+func TestSearchConquestSkipsTablebase(t *testing.T) {
+	// The tablebase assumes that no conquest follows, searches of conquests must search the position
+	result := NewSearcher(SearchOptions{TimeBudget: 50 * time.Millisecond, ConquestProbability: 1}).Search(context.Background(), conquestPosition())
+	if result.Depth < 1 {
+		t.Error("Expected the position to be searched rather than looked up")
+	}
+	if !result.Conquest || result.Move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected a conquest of 2,0, got %+v", result)
+	}
+}
+
+func TestSearchConquestExpectedScore(t *testing.T) {
+	/*
+	   X O .
+	   Turn: X, two aligned symbols win
+	   Playing 2,0 draws. Conquering 1,0 wins, or lets O win at 2,0 when the challenge is lost.
+	*/
+	newGame := func() *GameLogic {
+		game := NewGameLogic(BoardRules{Width: 3, Height: 1, WinLength: 2})
+		game.Board[0][0] = common.P1
+		game.Board[1][0] = common.P2
+		game.SymbolCount = 2
+		return game
+	}
+
+	p := 0.75
+	result := NewSearcher(SearchOptions{ConquestProbability: p}).Search(context.Background(), newGame())
+	if !result.Conquest || result.Move != (Move{X: 1, Y: 0}) {
+		t.Fatalf("Expected a conquest of 1,0, got %+v", result)
+	}
+	if expected := int(p*(WinScore-1) - (1-p)*(WinScore-2)); result.Score != expected {
+		t.Errorf("Expected score %d, got %d", expected, result.Score)
+	}
+
+	// A likely loss of the challenge makes the draw better
+	result = NewSearcher(SearchOptions{ConquestProbability: 0.25}).Search(context.Background(), newGame())
+	if result.Conquest || result.Move != (Move{X: 2, Y: 0}) {
+		t.Errorf("Expected the drawing move 2,0, got %+v", result)
+	}
+}
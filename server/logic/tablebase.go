@@ -0,0 +1,361 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"slices"
+	"sync"
+
+	"Goonker/common"
+	"Goonker/server/assets"
+)
+
+// Tablebase constants
+const (
+	// Name of the embedded tablebase of the classic board
+	TablebaseFile = "tablebase.bin"
+
+	// Boards larger than MaxTablebaseCells cells are too large to enumerate
+	MaxTablebaseCells = 12
+
+	// Value of the positions that are unreachable or over
+	tablebaseMissing = math.MinInt8
+)
+
+// tablebaseMagic starts the tablebase files
+var tablebaseMagic = []byte("GKTB")
+
+// Error messages
+var (
+	ErrInvalidTablebase  = errors.New("invalid tablebase")
+	ErrTablebaseTooLarge = errors.New("board too large for a tablebase")
+)
+
+// Tablebase holds the perfect play value of every position of a small board
+// reachable from the empty board, through conquests included.
+//
+// Values assume that no conquest is tried from the position on: conquests depend on challenges,
+// so their actions are scored one ply deep with the expected value of their two outcomes.
+//
+// Positions are indexed by their cells in base 3, cell (x, y) being digit x*Height+y,
+// and by the player to move. The file format is the magic "GKTB", the width, the height
+// and the win length as bytes, then one signed byte per position: n > 0 for a win
+// in n plies of the player to move, -n for a loss in n plies, 0 for a draw and -128 for
+// the positions that are unreachable or over.
+type Tablebase struct {
+	Rules  BoardRules
+	values []int8
+}
+
+// defaultTablebase loads the embedded tablebase of the classic board once.
+var defaultTablebase = sync.OnceValue(func() *Tablebase {
+	data, err := assets.AssetsFS.ReadFile(TablebaseFile)
+	if err != nil {
+		log.Printf("Tablebase not loaded: %v", err)
+		return nil
+	}
+
+	table, err := ReadTablebase(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("Tablebase not loaded: %v", err)
+		return nil
+	}
+	return table
+})
+
+// DefaultTablebase returns the embedded tablebase of the classic board, nil if it is missing.
+func DefaultTablebase() *Tablebase {
+	return defaultTablebase()
+}
+
+// GenerateTablebase enumerates the positions reachable on a board and solves them.
+func GenerateTablebase(rules BoardRules) (*Tablebase, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	if rules.Width*rules.Height > MaxTablebaseCells {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTablebaseTooLarge, rules.Width, rules.Height)
+	}
+
+	table := newTablebase(rules)
+	for i := range table.values {
+		table.values[i] = tablebaseMissing
+	}
+
+	// Enumerate the positions reachable with placements and conquests, won or lost
+	reached := make([]bool, len(table.values))
+	queue := []*GameLogic{NewGameLogic(rules)}
+	reached[table.index(queue[0])] = true
+	for len(queue) > 0 {
+		game := queue[0]
+		queue = queue[1:]
+
+		for _, action := range Actions(game, true) {
+			for _, won := range []bool{true, false} {
+				if !won && !action.Conquest {
+					continue
+				}
+
+				child := game.Clone().(*GameLogic)
+				if err := PlayAction(child, game.Turn, action, won); err != nil {
+					return nil, err
+				}
+				if index := table.index(child); !reached[index] {
+					reached[index] = true
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+
+	// Solve every reachable position, placements never lead to an unreachable one
+	scores := make(map[int]int)
+	for index, ok := range reached {
+		if ok {
+			game := table.position(index)
+			if _, over := game.Outcome(); !over {
+				table.values[index] = scoreToPlies(table.solve(game, scores))
+			}
+		}
+	}
+	return table, nil
+}
+
+// newTablebase allocates the values of every position of a board.
+func newTablebase(rules BoardRules) *Tablebase {
+	positions := 2
+	for range rules.Width * rules.Height {
+		positions *= 3
+	}
+	return &Tablebase{Rules: rules, values: make([]int8, positions)}
+}
+
+// solve returns the negamax score of game without conquests, memoized in scores by position index.
+func (t *Tablebase) solve(game *GameLogic, scores map[int]int) int {
+	if winner, over := game.Outcome(); over {
+		if winner == common.Empty {
+			return 0
+		}
+		return -WinScore // The previous move won
+	}
+
+	index := t.index(game)
+	if score, ok := scores[index]; ok {
+		return score
+	}
+
+	best := -WinScore
+	for _, move := range game.LegalMoves() {
+		player := game.Turn
+		if err := game.ApplyMove(player, move.X, move.Y); err != nil {
+			continue
+		}
+		best = max(best, deferScore(-t.solve(game, scores)))
+		game.Undo()
+	}
+	scores[index] = best
+	return best
+}
+
+// ReadTablebase reads a tablebase written by WriteTo.
+func ReadTablebase(r io.Reader) (*Tablebase, error) {
+	header := make([]byte, len(tablebaseMagic)+3)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTablebase, err)
+	}
+	if !bytes.Equal(header[:len(tablebaseMagic)], tablebaseMagic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidTablebase)
+	}
+
+	size := header[len(tablebaseMagic):]
+	rules := BoardRules{Width: int(size[0]), Height: int(size[1]), WinLength: int(size[2])}
+	if err := rules.Validate(); err != nil || rules.Width*rules.Height > MaxTablebaseCells {
+		return nil, fmt.Errorf("%w: bad board %dx%d", ErrInvalidTablebase, rules.Width, rules.Height)
+	}
+
+	table := newTablebase(rules)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTablebase, err)
+	}
+	if len(data) != len(table.values) {
+		return nil, fmt.Errorf("%w: expected %d positions, got %d", ErrInvalidTablebase, len(table.values), len(data))
+	}
+	for i := range table.values {
+		table.values[i] = int8(data[i])
+	}
+	return table, nil
+}
+
+// WriteTo writes the tablebase in its file format.
+func (t *Tablebase) WriteTo(w io.Writer) (int64, error) {
+	data := slices.Clone(tablebaseMagic)
+	data = append(data, byte(t.Rules.Width), byte(t.Rules.Height), byte(t.Rules.WinLength))
+	for _, value := range t.values {
+		data = append(data, byte(value))
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Positions returns the number of solved positions.
+func (t *Tablebase) Positions() int {
+	count := 0
+	for _, value := range t.values {
+		if value != tablebaseMissing {
+			count++
+		}
+	}
+	return count
+}
+
+// Lookup returns the negamax score of game for the player to move, ok is false if the table does not hold it.
+// Games with conquest rules other than the defaults are refused, the table being solved under the default ones.
+func (t *Tablebase) Lookup(game Rules) (int, bool) {
+	board, ok := game.(*GameLogic)
+	if !ok || board.Rules != t.Rules {
+		return 0, false
+	}
+
+	value := t.values[t.index(board)]
+	if value == tablebaseMissing {
+		return 0, false
+	}
+	return pliesToScore(value), true
+}
+
+// ScoreActions scores the actions of the player to move in game, best first.
+// Conquests score the expected score of their outcomes, won with the given probability.
+// ok is false if the table does not hold a position reached by an action.
+func (t *Tablebase) ScoreActions(game Rules, actions []Action, conquestProbability float64) ([]ActionScore, bool) {
+	if board, ok := game.(*GameLogic); !ok || board.Rules != t.Rules {
+		return nil, false
+	}
+
+	game = game.Clone()
+	player := game.CurrentTurn()
+
+	scores := make([]ActionScore, 0, len(actions))
+	for _, action := range actions {
+		expected := 0.0
+		for _, outcome := range outcomes(action, conquestProbability) {
+			if outcome.probability <= 0 {
+				continue
+			}
+			score, ok := t.scoreOutcome(game, player, action, outcome.won)
+			if !ok {
+				return nil, false
+			}
+			expected += outcome.probability * float64(score)
+		}
+		scores = append(scores, ActionScore{Action: action, Score: int(expected)})
+	}
+
+	slices.SortStableFunc(scores, func(a, b ActionScore) int {
+		return b.Score - a.Score
+	})
+	return scores, true
+}
+
+// scoreOutcome plays an action with the given conquest outcome, looks up the position and undoes the action.
+func (t *Tablebase) scoreOutcome(game Rules, player common.PlayerID, action Action, won bool) (int, bool) {
+	if err := PlayAction(game, player, action, won); err != nil {
+		return 0, false
+	}
+	defer game.Undo()
+
+	if winner, over := game.Outcome(); over {
+		if winner == player {
+			return WinScore - 1, true
+		}
+		return 0, true
+	}
+
+	score, ok := t.Lookup(game)
+	return deferScore(-score), ok
+}
+
+// index returns the position index of a game.
+func (t *Tablebase) index(game *GameLogic) int {
+	index, digit := 0, 1
+	for x := range t.Rules.Width {
+		for y := range t.Rules.Height {
+			index += int(game.Board[x][y]) * digit
+			digit *= 3
+		}
+	}
+	if game.Turn == common.P2 {
+		index += digit
+	}
+	return index
+}
+
+// position rebuilds the game of a position index.
+func (t *Tablebase) position(index int) *GameLogic {
+	game := NewGameLogic(t.Rules)
+	cells := len(t.values) / 2
+	if index >= cells {
+		game.Turn = common.P2
+		index -= cells
+	}
+
+	for x := range t.Rules.Width {
+		for y := range t.Rules.Height {
+			game.Board[x][y] = common.PlayerID(index % 3)
+			if game.Board[x][y] != common.Empty {
+				game.SymbolCount++
+			}
+			index /= 3
+		}
+	}
+
+	// A full board or a line ends the game, only the lines of the previous player can exist
+	for x := range t.Rules.Width {
+		for y := range t.Rules.Height {
+			if game.Board[x][y] != common.Empty && game.checkWinAt(x, y) {
+				game.Winner, game.GameOver = game.Board[x][y], true
+				return game
+			}
+		}
+	}
+	game.GameOver = game.SymbolCount >= game.maxMoves()
+	return game
+}
+
+// deferScore moves a score one ply further from the root: wins and losses take one more ply.
+func deferScore(score int) int {
+	switch {
+	case score > 0:
+		return score - 1
+	case score < 0:
+		return score + 1
+	}
+	return 0
+}
+
+// scoreToPlies converts a negamax score to a tablebase value.
+func scoreToPlies(score int) int8 {
+	switch {
+	case score > 0:
+		return int8(WinScore - score)
+	case score < 0:
+		return int8(-WinScore - score)
+	}
+	return 0
+}
+
+// pliesToScore converts a tablebase value to a negamax score.
+func pliesToScore(value int8) int {
+	switch {
+	case value > 0:
+		return WinScore - int(value)
+	case value < 0:
+		return -WinScore - int(value)
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"Goonker/common"
+)
+
+func TestTablebaseUpToDate(t *testing.T) {
+	embedded := DefaultTablebase()
+	if embedded == nil {
+		t.Fatal("Expected the embedded tablebase to load")
+	}
+
+	generated, err := GenerateTablebase(ClassicRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tablebaseBytes(t, embedded), tablebaseBytes(t, generated)) {
+		t.Error("Expected the embedded tablebase to match the generator, run go run ./cmd/tablebase")
+	}
+}
+
+func TestTablebaseMatchesReference(t *testing.T) {
+	table := DefaultTablebase()
+	if table == nil {
+		t.Fatal("Expected the embedded tablebase to load")
+	}
+
+	/*
+	   X X .
+	   X . .
+	   O . .
+	   Turn: O, only reachable through a conquest of X
+	*/
+	conquered := NewGameLogic(ClassicRules)
+	conquered.Board[0][0] = common.P1
+	conquered.Board[1][0] = common.P1
+	conquered.Board[0][1] = common.P1
+	conquered.Board[0][2] = common.P2
+	conquered.SymbolCount = 4
+	conquered.Turn = common.P2
+
+	opening := NewGameLogic(ClassicRules)
+	mustApply(t, opening, common.P1, 1, 1)
+	mustApply(t, opening, common.P2, 0, 1)
+
+	for _, game := range []*GameLogic{NewGameLogic(ClassicRules), opening, conquered} {
+		score, ok := table.Lookup(game)
+		if !ok {
+			t.Fatalf("Expected the position to be in the tablebase:\n%v", game.Board)
+		}
+		if expected := referenceNegamax(game, game.Turn, 0); score != expected {
+			t.Errorf("Expected score %d, got %d for\n%v", expected, score, game.Board)
+		}
+	}
+}
+
+func TestTablebaseConquestScore(t *testing.T) {
+	table := DefaultTablebase()
+	if table == nil {
+		t.Fatal("Expected the embedded tablebase to load")
+	}
+
+	// A sure conquest of 2,0 wins the row at once
+	game := conquestPosition()
+	scores, ok := table.ScoreActions(game, Actions(game, true), 1)
+	if !ok {
+		t.Fatal("Expected the actions to be in the tablebase")
+	}
+	if best := scores[0]; !best.Conquest || best.Move != (Move{X: 2, Y: 0}) || best.Score != WinScore-1 {
+		t.Errorf("Expected a winning conquest of 2,0, got %+v", best)
+	}
+}
+
+func TestTablebaseReadErrors(t *testing.T) {
+	table, err := GenerateTablebase(BoardRules{Width: 2, Height: 2, WinLength: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := tablebaseBytes(t, table)
+
+	read, err := ReadTablebase(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Rules != table.Rules || read.Positions() != table.Positions() {
+		t.Errorf("Expected the tablebase to round trip, got %+v", read.Rules)
+	}
+
+	for _, invalid := range [][]byte{nil, []byte("XXXX\x03\x03\x03"), data[:len(data)-1]} {
+		if _, err := ReadTablebase(bytes.NewReader(invalid)); !errors.Is(err, ErrInvalidTablebase) {
+			t.Errorf("Expected ErrInvalidTablebase, got %v", err)
+		}
+	}
+
+	if _, err := GenerateTablebase(BoardRules{Width: 4, Height: 4, WinLength: 3}); !errors.Is(err, ErrTablebaseTooLarge) {
+		t.Errorf("Expected ErrTablebaseTooLarge, got %v", err)
+	}
+}
+
+// tablebaseBytes writes a tablebase to memory.
+func tablebaseBytes(t *testing.T, table *Tablebase) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	if _, err := table.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,351 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"Goonker/common"
+)
+
+// Ultimate Tic-Tac-Toe constants
+const (
+	// Each cell of the big board is a SubBoardSize x SubBoardSize board
+	SubBoardSize = 3
+	UltimateSize = SubBoardSize * SubBoardSize
+
+	// NoActiveBoard means the player may play in any open sub-board
+	NoActiveBoard = -1
+
+	// Display separator between sub-boards (for console debug)
+	SeparatorSubV = " || "
+	SeparatorSubH = "="
+)
+
+// Error messages
+var (
+	ErrWrongSubBoard   = errors.New("move must be played in the active sub-board")
+	ErrSubBoardDecided = errors.New("sub-board is already decided")
+)
+
+// UltimateLogic manages the state of an Ultimate Tic-Tac-Toe game.
+// The board is addressed with global coordinates, the sub-board of a cell is (x/3, y/3).
+type UltimateLogic struct {
+	Board     [][]common.PlayerID // Indexed as Board[x][y], UltimateSize x UltimateSize
+	SubBoards [][]common.PlayerID // Winner of each sub-board, Empty while undecided
+	ActiveX   int                 // Sub-board the current player is sent to, or NoActiveBoard
+	ActiveY   int
+	Turn      common.PlayerID
+	Winner    common.PlayerID
+	GameOver  bool
+
+	// Number of symbols in each sub-board
+	subCounts [][]int
+
+	moveHistory
+}
+
+// NewUltimateLogic initializes a new Ultimate Tic-Tac-Toe game.
+func NewUltimateLogic() *UltimateLogic {
+	subCounts := make([][]int, SubBoardSize)
+	for i := range subCounts {
+		subCounts[i] = make([]int, SubBoardSize)
+	}
+
+	return &UltimateLogic{
+		Board:     newBoard(UltimateSize, UltimateSize),
+		SubBoards: newBoard(SubBoardSize, SubBoardSize),
+		ActiveX:   NoActiveBoard,
+		ActiveY:   NoActiveBoard,
+		Turn:      common.P1, // X always starts
+		subCounts: subCounts,
+	}
+}
+
+// Clone returns a deep copy of the game state.
+func (u *UltimateLogic) Clone() Rules {
+	clone := *u
+	clone.Board = cloneBoard(u.Board)
+	clone.SubBoards = cloneBoard(u.SubBoards)
+	clone.subCounts = make([][]int, SubBoardSize)
+	for i := range u.subCounts {
+		clone.subCounts[i] = append([]int(nil), u.subCounts[i]...)
+	}
+	clone.moveHistory = u.moveHistory.clone()
+	return &clone
+}
+
+// CurrentTurn returns the player expected to move.
+func (u *UltimateLogic) CurrentTurn() common.PlayerID {
+	return u.Turn
+}
+
+// Outcome returns the winner (Empty for a draw) and whether the game is over.
+func (u *UltimateLogic) Outcome() (common.PlayerID, bool) {
+	return u.Winner, u.GameOver
+}
+
+// LegalMoves lists the empty cells of the sub-boards the current player may play.
+func (u *UltimateLogic) LegalMoves() []Move {
+	if u.GameOver {
+		return nil
+	}
+
+	var moves []Move
+	for x := range UltimateSize {
+		for y := range UltimateSize {
+			if u.Board[x][y] == common.Empty && u.isPlayable(x, y) == nil {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+	}
+	return moves
+}
+
+// ConquestMoves lists the opponent cells of the sub-boards the current player may play.
+func (u *UltimateLogic) ConquestMoves() []Move {
+	if u.GameOver {
+		return nil
+	}
+
+	var moves []Move
+	for x := range UltimateSize {
+		for y := range UltimateSize {
+			if u.ShouldTriggerChallenge(u.Turn, x, y) {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+	}
+	return moves
+}
+
+// State serializes the game for the clients.
+func (u *UltimateLogic) State() common.UpdatePayload {
+	return common.UpdatePayload{
+		Board:     u.Board,
+		Width:     UltimateSize,
+		Height:    UltimateSize,
+		Turn:      u.Turn,
+		SubBoards: u.SubBoards,
+		ActiveX:   u.ActiveX,
+		ActiveY:   u.ActiveY,
+	}
+}
+
+// isSubBoardOpen checks if the sub-board (sx, sy) can still be played.
+func (u *UltimateLogic) isSubBoardOpen(sx, sy int) bool {
+	return u.SubBoards[sx][sy] == common.Empty && u.subCounts[sx][sy] < SubBoardSize*SubBoardSize
+}
+
+// isPlayable checks if the cell (x, y) lies in a sub-board the current player may play.
+func (u *UltimateLogic) isPlayable(x, y int) error {
+	sx, sy := x/SubBoardSize, y/SubBoardSize
+	if !u.isSubBoardOpen(sx, sy) {
+		return ErrSubBoardDecided
+	}
+	if u.ActiveX != NoActiveBoard && (sx != u.ActiveX || sy != u.ActiveY) {
+		return ErrWrongSubBoard
+	}
+	return nil
+}
+
+// ShouldTriggerChallenge checks if a challenge should be triggered
+func (u *UltimateLogic) ShouldTriggerChallenge(player common.PlayerID, x, y int) bool {
+	if x < 0 || x >= UltimateSize || y < 0 || y >= UltimateSize {
+		return false
+	}
+	if u.isPlayable(x, y) != nil {
+		return false
+	}
+	return u.Board[x][y] != player && u.Board[x][y] != common.Empty
+}
+
+// ApplyMove attempts to play a move. Returns an error if invalid.
+func (u *UltimateLogic) ApplyMove(player common.PlayerID, x, y int) error {
+	// Validate move
+	if u.GameOver {
+		return ErrGameOver
+	}
+	if player != u.Turn {
+		return ErrNotYourTurn
+	}
+	if x < 0 || x >= UltimateSize || y < 0 || y >= UltimateSize {
+		return ErrOutOfBounds
+	}
+	if err := u.isPlayable(x, y); err != nil {
+		return err
+	}
+	if u.Board[x][y] == player {
+		return ErrCellOccupied
+	}
+
+	rec := u.record(player, x, y, u.Board[x][y])
+	rec.activeX, rec.activeY = u.ActiveX, u.ActiveY
+
+	sx, sy := x/SubBoardSize, y/SubBoardSize
+	if u.Board[x][y] == common.Empty {
+		// Place the player symbol
+		u.Board[x][y] = player
+		u.subCounts[sx][sy]++
+
+		// A line in the sub-board wins it
+		if checkWinInSubBoard(u.Board, x, y) {
+			u.SubBoards[sx][sy] = player
+		}
+	}
+
+	// The position inside the sub-board sends the opponent to the matching sub-board
+	u.ActiveX, u.ActiveY = x%SubBoardSize, y%SubBoardSize
+	if !u.isSubBoardOpen(u.ActiveX, u.ActiveY) {
+		u.ActiveX, u.ActiveY = NoActiveBoard, NoActiveBoard
+	}
+
+	// Check for win or draw on the big board
+	if u.SubBoards[sx][sy] == player && checkWinInSubBoard(u.SubBoards, sx, sy) {
+		u.Winner = player
+		u.GameOver = true
+	} else if u.allSubBoardsDecided() {
+		u.GameOver = true // Draw
+	} else {
+		// Toggle turn
+		if u.Turn == common.P1 {
+			u.Turn = common.P2
+		} else {
+			u.Turn = common.P1
+		}
+	}
+
+	return nil
+}
+
+// DeleteMove empties the given board cell
+func (u *UltimateLogic) DeleteMove(x, y int) {
+	u.markDeleted(x, y, u.Board[x][y])
+	u.Board[x][y] = common.Empty
+	u.subCounts[x/SubBoardSize][y/SubBoardSize]--
+}
+
+// RestoreDeleted gives back the cell emptied by DeleteMove, when the conquering move is refused.
+func (u *UltimateLogic) RestoreDeleted() {
+	if cell, owner := u.takeDeleted(); cell != nil {
+		u.Board[cell.X][cell.Y] = owner
+		u.subCounts[cell.X/SubBoardSize][cell.Y/SubBoardSize]++
+	}
+}
+
+// ForfeitCell empties a cell of the player of the last move, as the penalty of a failed conquest.
+// A sub-board already won stays won.
+func (u *UltimateLogic) ForfeitCell(x, y int) error {
+	if u.GameOver {
+		return ErrGameOver
+	}
+	if x < 0 || x >= UltimateSize || y < 0 || y >= UltimateSize {
+		return ErrOutOfBounds
+	}
+	if err := u.markForfeit(x, y, u.Board[x][y]); err != nil {
+		return err
+	}
+
+	u.Board[x][y] = common.Empty
+	u.subCounts[x/SubBoardSize][y/SubBoardSize]--
+	return nil
+}
+
+// Undo reverts the last move, a won conquest gives the cell back to its previous owner.
+func (u *UltimateLogic) Undo() error {
+	last, err := u.pop()
+	if err != nil {
+		return err
+	}
+
+	// A cell lost as a penalty goes back to the player
+	if last.Forfeit != nil {
+		u.Board[last.Forfeit.X][last.Forfeit.Y] = last.Player
+		u.subCounts[last.Forfeit.X/SubBoardSize][last.Forfeit.Y/SubBoardSize]++
+	}
+
+	sx, sy := last.X/SubBoardSize, last.Y/SubBoardSize
+	if u.Board[last.X][last.Y] != common.Empty && last.Previous == common.Empty {
+		u.subCounts[sx][sy]--
+	}
+	u.Board[last.X][last.Y] = last.Previous
+
+	// The move was only legal in an undecided sub-board
+	u.SubBoards[sx][sy] = common.Empty
+	u.ActiveX, u.ActiveY = last.activeX, last.activeY
+
+	// The game could not be over before the move
+	u.Turn = last.Player
+	u.Winner = common.Empty
+	u.GameOver = false
+
+	return nil
+}
+
+// allSubBoardsDecided checks if no sub-board can be played anymore.
+func (u *UltimateLogic) allSubBoardsDecided() bool {
+	for sx := range SubBoardSize {
+		for sy := range SubBoardSize {
+			if u.isSubBoardOpen(sx, sy) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkWinInSubBoard checks if the symbol at (x, y) completes a line
+// inside the SubBoardSize x SubBoardSize block containing it.
+func checkWinInSubBoard(board [][]common.PlayerID, x, y int) bool {
+	p := board[x][y]
+	if p == common.Empty {
+		return false
+	}
+
+	minX, minY := x-x%SubBoardSize, y-y%SubBoardSize
+	inBlock := func(cx, cy int) bool {
+		return cx >= minX && cx < minX+SubBoardSize && cy >= minY && cy < minY+SubBoardSize
+	}
+
+	for _, dir := range directions {
+		count := 1
+		for _, sign := range [2]int{1, -1} {
+			dx, dy := dir[0]*sign, dir[1]*sign
+			for cx, cy := x+dx, y+dy; inBlock(cx, cy) && board[cx][cy] == p; cx, cy = cx+dx, cy+dy {
+				count++
+			}
+		}
+		if count >= SubBoardSize {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintConsoleBoard renders the board state to the console for debugging.
+func (u *UltimateLogic) PrintConsoleBoard() {
+	for y := range UltimateSize {
+		var line strings.Builder
+		for x := range UltimateSize {
+			switch u.Board[x][y] {
+			case common.P1:
+				line.WriteString(SymbolP1)
+			case common.P2:
+				line.WriteString(SymbolP2)
+			default:
+				line.WriteString(SymbolEmpty)
+			}
+
+			// Separate sub-boards
+			if x < UltimateSize-1 && x%SubBoardSize == SubBoardSize-1 {
+				line.WriteString(SeparatorSubV)
+			}
+		}
+		fmt.Println(line.String())
+
+		// Print horizontal separator only between sub-boards
+		if y < UltimateSize-1 && y%SubBoardSize == SubBoardSize-1 {
+			fmt.Println(strings.Repeat(SeparatorSubH, UltimateSize+2*len(SeparatorSubV)))
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"Goonker/common"
+	"testing"
+)
+
+func TestNewUltimateLogic(t *testing.T) {
+	game := NewUltimateLogic()
+	if len(game.Board) != UltimateSize || len(game.Board[0]) != UltimateSize {
+		t.Errorf("Expected %dx%d board", UltimateSize, UltimateSize)
+	}
+	if game.ActiveX != NoActiveBoard || game.ActiveY != NoActiveBoard {
+		t.Error("Expected first move to be free")
+	}
+	if game.Turn != common.P1 {
+		t.Errorf("Expected Turn to be P1, got %d", game.Turn)
+	}
+}
+
+func TestUltimateActiveSubBoard(t *testing.T) {
+	game := NewUltimateLogic()
+
+	// X plays the top-right cell of the center sub-board, O is sent to the top-right sub-board
+	mustApply(t, game, common.P1, 5, 3)
+	if game.ActiveX != 2 || game.ActiveY != 0 {
+		t.Fatalf("Expected active sub-board 2,0, got %d,%d", game.ActiveX, game.ActiveY)
+	}
+
+	// O cannot play outside the active sub-board
+	if err := game.ApplyMove(common.P2, 0, 0); err != ErrWrongSubBoard {
+		t.Errorf("Expected ErrWrongSubBoard, got %v", err)
+	}
+
+	// O plays the center of the top-right sub-board, X is sent to the center
+	mustApply(t, game, common.P2, 7, 1)
+	if game.ActiveX != 1 || game.ActiveY != 1 {
+		t.Errorf("Expected active sub-board 1,1, got %d,%d", game.ActiveX, game.ActiveY)
+	}
+}
+
+func TestUltimateSubBoardWin(t *testing.T) {
+	game := NewUltimateLogic()
+
+	// O already has two symbols on the middle row of the top-left sub-board
+	game.Board[0][1] = common.P2
+	game.Board[1][1] = common.P2
+	game.Board[0][0] = common.P1
+	game.Board[3][3] = common.P1
+	game.subCounts[0][0] = 3
+	game.subCounts[1][1] = 1
+	game.ActiveX, game.ActiveY = 0, 0
+	game.Turn = common.P2
+
+	mustApply(t, game, common.P2, 2, 1) // O completes the row, X is sent to 2,1
+
+	if game.SubBoards[0][0] != common.P2 {
+		t.Fatalf("Expected O to win sub-board 0,0, got %d", game.SubBoards[0][0])
+	}
+
+	// Decided sub-boards cannot be played, X is free to choose
+	if game.ActiveX != 2 || game.ActiveY != 1 {
+		t.Fatalf("Expected active sub-board 2,1, got %d,%d", game.ActiveX, game.ActiveY)
+	}
+	mustApply(t, game, common.P1, 6, 3) // -> O to 0,0 which is decided
+	if game.ActiveX != NoActiveBoard {
+		t.Errorf("Expected free move after being sent to a decided sub-board, got %d,%d", game.ActiveX, game.ActiveY)
+	}
+	if err := game.ApplyMove(common.P2, 0, 1); err != ErrSubBoardDecided {
+		t.Errorf("Expected ErrSubBoardDecided, got %v", err)
+	}
+}
+
+func TestUltimateGameWin(t *testing.T) {
+	game := NewUltimateLogic()
+
+	// Force the sub-board results and let X complete the big diagonal
+	game.SubBoards[0][0] = common.P1
+	game.SubBoards[1][1] = common.P1
+	game.Board[6][6] = common.P1
+	game.Board[7][7] = common.P1
+	game.subCounts[2][2] = 2
+
+	mustApply(t, game, common.P1, 8, 8)
+	if !game.GameOver || game.Winner != common.P1 {
+		t.Errorf("Expected P1 to win, got over=%v winner=%d", game.GameOver, game.Winner)
+	}
+}
+
+func TestUltimateShouldTriggerChallenge(t *testing.T) {
+	game := NewUltimateLogic()
+	game.Board[4][4] = common.P2
+	game.subCounts[1][1] = 1
+
+	if !game.ShouldTriggerChallenge(common.P1, 4, 4) {
+		t.Error("Expected trigger challenge on an opponent cell")
+	}
+
+	// Outside of the active sub-board, no challenge
+	game.ActiveX, game.ActiveY = 0, 0
+	if game.ShouldTriggerChallenge(common.P1, 4, 4) {
+		t.Error("Expected no challenge outside the active sub-board")
+	}
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"math/rand/v2"
+	"sync"
+
+	"Goonker/common"
+)
+
+// Zobrist hashing constants
+const (
+	zobristSeed   = 0x676F6F6E6B6572 // "goonker"
+	zobristExtras = 32               // Keys for variant specific state, e.g. the active sub-board
+)
+
+// Hasher is implemented by the variants whose positions can be stored in a transposition table.
+type Hasher interface {
+	// Hash returns the Zobrist hash of the position, including the player to move.
+	Hash() uint64
+}
+
+// zobristTable holds the random keys XORed together to hash a position.
+type zobristTable struct {
+	cells  [][2]uint64 // One key per cell and player
+	turn   uint64      // Set when P2 is to move
+	extras [zobristExtras]uint64
+}
+
+// zobristTables caches one table per board layout, keyed by an identifier of the layout.
+var zobristTables sync.Map
+
+// zobristFor returns the keys for a board of the given number of cells.
+// The layout identifies the variant so that different rules never share keys.
+func zobristFor(layout uint64, cells int) *zobristTable {
+	if table, ok := zobristTables.Load(layout); ok {
+		return table.(*zobristTable)
+	}
+
+	// Deterministic keys, so that hashes are stable between runs
+	rng := rand.New(rand.NewPCG(zobristSeed, layout))
+	table := &zobristTable{
+		cells: make([][2]uint64, cells),
+		turn:  rng.Uint64(),
+	}
+	for i := range table.cells {
+		table.cells[i] = [2]uint64{rng.Uint64(), rng.Uint64()}
+	}
+	for i := range table.extras {
+		table.extras[i] = rng.Uint64()
+	}
+
+	actual, _ := zobristTables.LoadOrStore(layout, table)
+	return actual.(*zobristTable)
+}
+
+// mixHash spreads the bits of a small value over a hash key, with the splitmix64 finalizer.
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// hashBoard hashes the cells of a board and the player to move.
+func (z *zobristTable) hashBoard(board [][]common.PlayerID, turn common.PlayerID) uint64 {
+	var hash uint64
+	height := 0
+	if len(board) > 0 {
+		height = len(board[0])
+	}
+
+	for x := range board {
+		for y, cell := range board[x] {
+			if cell != common.Empty {
+				hash ^= z.cells[x*height+y][cell-1]
+			}
+		}
+	}
+	if turn == common.P2 {
+		hash ^= z.turn
+	}
+	return hash
+}
+
+// Hash returns the Zobrist hash of the position.
+func (g *GameLogic) Hash() uint64 {
+	layout := uint64(g.Rules.Width)<<32 | uint64(g.Rules.Height)<<16 | uint64(g.Rules.WinLength)
+	return zobristFor(layout, g.maxMoves()).hashBoard(g.Board, g.Turn)
+}
+
+// Hash returns the Zobrist hash of the position, including the active sub-board and the sub-board winners.
+func (u *UltimateLogic) Hash() uint64 {
+	const layout = 1 << 48 // Distinct from any m,n,k layout
+	table := zobristFor(layout, UltimateSize*UltimateSize)
+
+	// Extra keys: the active sub-board or none, then one per sub-board and winner
+	active := SubBoardSize * SubBoardSize
+	if u.ActiveX != NoActiveBoard {
+		active = u.ActiveX*SubBoardSize + u.ActiveY
+	}
+	hash := table.hashBoard(u.Board, u.Turn) ^ table.extras[active]
+
+	// Won sub-boards stay won when a conquest or a penalty empties their cells, so winners do not follow from the cells
+	for sx := range SubBoardSize {
+		for sy := range SubBoardSize {
+			if winner := u.SubBoards[sx][sy]; winner != common.Empty {
+				hash ^= table.extras[SubBoardSize*SubBoardSize+1+2*(sx*SubBoardSize+sy)+int(winner-1)]
+			}
+		}
+	}
+	return hash
+}
@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"Goonker/common"
 	"Goonker/server/hub"
+	"Goonker/server/logic"
 
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
@@ -31,6 +35,42 @@ const (
 
 // main is the entry point of the server application.
 func main() {
+	flag.StringVar(&hub.RecordsDir, "records", "", "directory where finished games are saved (disabled if empty)")
+	botCommand := flag.String("bot", "", "command line of the external bot process (disabled if empty)")
+	policyPath := flag.String("policy", "", "policy file of the learned bot engine (disabled if empty)")
+	flag.DurationVar(&hub.BotThinkDelay, "think-delay", hub.BotThinkDelay, "delay before the moves of the bots")
+	flag.Float64Var(&hub.ChallengeMix.Generated, "generated", hub.ChallengeMix.Generated, "share of generated challenges, from 0 (none) to 1 (only generated ones)")
+	flag.Float64Var(&hub.ChallengeMix.Minigames, "minigames", hub.ChallengeMix.Minigames, "share of minigames played instead of questions, from 0 (none) to 1 (only minigames)")
+	challengesDir := flag.String("challenges", "", "directory of challenge packs added to the embedded ones, reloaded on changes (disabled if empty)")
+	flag.Parse()
+
+	hub.ExternalBotCommand = strings.Fields(*botCommand)
+
+	if *policyPath != "" {
+		policy, err := logic.LoadPolicy(*policyPath)
+		if err != nil {
+			log.Fatal("Cannot load policy: ", err)
+		}
+		hub.LearnedPolicy = policy
+		log.Printf("Learned policy loaded (%s, %d episodes)", policy.Mode, policy.Episodes)
+	}
+
+	if *challengesDir != "" {
+		watcher, err := logic.NewChallengeWatcher(*challengesDir)
+		if err != nil {
+			log.Fatal("Cannot load challenge packs: ", err)
+		}
+		challenges, _ := logic.Challenges()
+		log.Printf("Challenge packs loaded, %d challenges", len(challenges))
+		go watcher.Run(context.Background(), logic.DefaultChallengeReloadInterval)
+	}
+
+	if hub.RecordsDir != "" {
+		if err := os.MkdirAll(hub.RecordsDir, 0o755); err != nil {
+			log.Fatal("Cannot create records directory: ", err)
+		}
+	}
+
 	// Register the WebSocket handler
 	http.HandleFunc(WsRoute, wsHandler)
 
@@ -89,13 +129,13 @@ func wsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// Let the Hub assign the player to a new or existing room
-			room, err := hub.GlobalHub.CreateRoom(joinData.RoomID, joinData.IsBot)
+			room, err := hub.GlobalHub.CreateRoom(joinData)
 			if err != nil {
 				log.Printf("Failed to create room '%s': %v", joinData.RoomID, err)
 				_ = c.Close(websocket.StatusInternalError, "Failed to create room")
 				return
 			}
-			log.Printf("Client joining room '%s' (Bot: %v)", joinData.RoomID, joinData.IsBot)
+			log.Printf("Client joining room '%s' (Bot: %v, Mode: %s, Difficulty: %s, Engine: %s)", joinData.RoomID, joinData.IsBot, room.Mode, room.Difficulty, room.Engine)
 			pid := room.AddPlayer(c)
 
 			// Validation of assigned PlayerID, otherwise room is full
@@ -118,7 +158,7 @@ func wsHandler(w http.ResponseWriter, r *http.Request) {
 			rooms := hub.GlobalHub.GetAvailableRooms()
 
 			// Send the list back to the client
-			payload := common.RoomsPayload{Rooms: rooms}
+			payload := common.RoomsPayload{Rooms: rooms, Modes: logic.Modes(), Engines: hub.Engines()}
 			data, err := json.Marshal(payload)
 			if err != nil {
 				log.Printf("Error marshaling rooms payload: %v", err)